@@ -0,0 +1,65 @@
+package adr
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrNotFound is matched by errors.Is for the errors of looking up records that do not exist
+	ErrNotFound = errors.New("record not found")
+	// ErrExists is matched by errors.Is when a new record would overwrite an existing file
+	ErrExists = errors.New("record already exists")
+)
+
+// NotFoundError is returned when no record has the number a Ref refers to
+type NotFoundError struct {
+	Ref Ref
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("no record %s found", e.Ref)
+}
+
+// Is lets errors.Is match the error with ErrNotFound
+func (e *NotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// ExistsError is returned when Create would overwrite the file at Path
+type ExistsError struct {
+	Path string
+}
+
+func (e *ExistsError) Error() string {
+	return fmt.Sprintf("unable to create %s, it already exists", e.Path)
+}
+
+// Is lets errors.Is match the error with ErrExists
+func (e *ExistsError) Is(target error) bool {
+	return target == ErrExists
+}
+
+// InvalidRefError is returned when a reference cannot be read, or names a repository or category that is not in
+// .adr.yaml
+type InvalidRefError struct {
+	Ref string
+	Err error
+}
+
+func (e *InvalidRefError) Error() string {
+	return fmt.Sprintf("invalid reference '%s': %v", e.Ref, e.Err)
+}
+
+func (e *InvalidRefError) Unwrap() error {
+	return e.Err
+}
+
+// InvalidStatusError is returned when a record is given an empty status
+type InvalidStatusError struct {
+	Status Status
+}
+
+func (e *InvalidStatusError) Error() string {
+	return fmt.Sprintf("invalid status '%s'", e.Status)
+}
@@ -0,0 +1,150 @@
+package adr
+
+import (
+	"fmt"
+	"github.com/fleetingclarity/adr/config"
+	"strings"
+	"time"
+)
+
+// Status is the status of a record. Repositories may use statuses of their own, the constants are the ones the
+// adr tool itself gives records.
+type Status string
+
+const (
+	Proposed   Status = "Proposed"
+	Accepted   Status = "Accepted"
+	Rejected   Status = "Rejected"
+	Deprecated Status = "Deprecated"
+	Superseded Status = "Superseded"
+)
+
+// Is reports whether s is the status other, ignoring case and anything written after the first word, e.g. a
+// 'Superseded by 7' status is Superseded
+func (s Status) Is(other Status) bool {
+	fields := strings.Fields(string(s))
+	return len(fields) > 0 && strings.EqualFold(fields[0], string(other))
+}
+
+// Ref refers to a record by its number, in a category and in another repository of the same .adr.yaml when they
+// are set
+type Ref struct {
+	// Repository is the name of the repository in .adr.yaml, empty for the repository the record is looked up from
+	Repository string `json:"repository,omitempty"`
+	// Category is the name or prefix of a category declared under adr.categories, empty for uncategorized records
+	Category string `json:"category,omitempty"`
+	Number   int    `json:"number"`
+}
+
+// ParseRef parses a reference written the way the adr tool takes them, such as '12', 'SEC-12', 'security/12' or
+// 'billing:12'
+func ParseRef(s string) (Ref, error) {
+	r, err := config.ParseRef(s)
+	if err != nil {
+		return Ref{}, &InvalidRefError{Ref: s, Err: err}
+	}
+	return Ref{Repository: r.Repository, Category: r.Category, Number: r.Number}, nil
+}
+
+// String writes the reference the way ParseRef reads it
+func (r Ref) String() string {
+	s := fmt.Sprint(r.Number)
+	if r.Category != "" {
+		s = r.Category + "/" + s
+	}
+	if r.Repository != "" {
+		s = r.Repository + ":" + s
+	}
+	return s
+}
+
+// Record is an architecture decision record as read from its markdown
+type Record struct {
+	Ref Ref `json:"ref"`
+	// ID is the number as written in the file name, with the category prefix if any, e.g. 007 or SEC-007
+	ID     string `json:"id"`
+	Path   string `json:"path"`
+	Title  string `json:"title"`
+	Date   string `json:"date,omitempty"`
+	Status Status `json:"status"`
+	// Metadata holds the 'Key: value' lines under the heading, or the front matter, keyed by lower case key
+	Metadata map[string]string `json:"metadata,omitempty"`
+	Links    []Link            `json:"links,omitempty"`
+	Reviews  []Review          `json:"reviews,omitempty"`
+	Sections []Section         `json:"sections,omitempty"`
+}
+
+// Link is a relation from a record to another, e.g. 'Superseded by' or 'Links to'. Target is the path of the other
+// record relative to this one.
+type Link struct {
+	Relation string `json:"relation"`
+	Target   string `json:"target"`
+	Message  string `json:"message,omitempty"`
+}
+
+// Review is a review requested from or given by one reviewer, State is requested or approved
+type Review struct {
+	Reviewer string `json:"reviewer"`
+	State    string `json:"state"`
+	Date     string `json:"date,omitempty"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+// Section is a '## ' section of a record
+type Section struct {
+	Name    string `json:"name"`
+	Content string `json:"content"`
+}
+
+// Section returns the content of the named section and whether the record has it
+func (r *Record) Section(name string) (string, bool) {
+	for _, s := range r.Sections {
+		if strings.EqualFold(s.Name, name) {
+			return s.Content, true
+		}
+	}
+	return "", false
+}
+
+// Draft is what Create needs to know about a new record, everything but the title is optional
+type Draft struct {
+	Title string
+	// Category is the name or prefix of a category declared under adr.categories
+	Category string
+	// Date backdates the record, it is dated now when zero
+	Date      time.Time
+	Author    string
+	Deciders  []string
+	Consulted []string
+	Informed  []string
+	// Fields are the values of the fields declared under adr.fields, by name
+	Fields map[string]string
+	// Sections replace the template content of the named sections, e.g. Context
+	Sections map[string]string
+	// ReviewBy and Expires are revisit dates or periods after the date of the record, e.g. 2025-06-01 or 6m
+	ReviewBy string
+	Expires  string
+}
+
+// values returns the template values of the draft the way 'adr add' sets them, with the date in the given layout
+func (d Draft) values(author, layout string) map[string]string {
+	v := make(map[string]string)
+	for k, f := range d.Fields {
+		v[k] = f
+	}
+	v["Title"] = d.Title
+	v["Category"] = d.Category
+	if !d.Date.IsZero() {
+		v["Date"] = d.Date.Format(layout)
+	}
+	v["ReviewBy"] = d.ReviewBy
+	v["Expires"] = d.Expires
+	if d.Author != "" {
+		author = d.Author
+	}
+	v[config.RoleAuthor] = author
+	v[config.RoleDeciders] = strings.Join(d.Deciders, ",")
+	v[config.RoleConsulted] = strings.Join(d.Consulted, ",")
+	v[config.RoleInformed] = strings.Join(d.Informed, ",")
+	return v
+}
@@ -0,0 +1,276 @@
+// Package adr reads and changes architecture decision records the way the adr command line tool does, so programs
+// can embed it rather than run the tool.
+//
+// A Repository is opened from the directory holding .adr.yaml and follows its settings: templates, metadata style,
+// categories, date format and so on. Records are referred to with a Ref, which ParseRef reads from the references
+// the tool takes, e.g. '12', 'SEC-12' or 'billing:12'.
+//
+//	repo, err := adr.Open("/src/service")
+//	if err != nil {
+//		return err
+//	}
+//	r, err := repo.Create(adr.Draft{Title: "Use Postgres", Deciders: []string{"alice"}})
+//	if err != nil {
+//		return err
+//	}
+//	_, err = repo.SetStatus(r.Ref, adr.Accepted)
+//
+// Repositories are read from and written to the disk unless they are opened with OpenFS, which takes any
+// storage.FS: e.g. a storage.Memory to work without temporary directories, or a storage.GitTree to read the records
+// of a commit straight from the object store.
+//
+// Errors about records are typed: a record that does not exist is a *NotFoundError, which errors.Is matches with
+// ErrNotFound, and a new record that would overwrite a file is an *ExistsError matched by ErrExists.
+package adr
+
+import (
+	"errors"
+	"github.com/fleetingclarity/adr/config"
+	"github.com/fleetingclarity/adr/storage"
+	"io/fs"
+	"path/filepath"
+	"strings"
+)
+
+// Repository is one ADR repository of a project, the records kept in Dir
+type Repository struct {
+	// Name is the name of the repository in .adr.yaml, empty for the default one
+	Name string
+	// Dir is the directory the records are kept in
+	Dir      string
+	fs       storage.FS
+	config   *config.Config
+	settings *config.ADR
+}
+
+// Open opens the default repository of the project in dir, using the default settings when dir has no .adr.yaml
+func Open(dir string) (*Repository, error) {
+	return OpenNamed(dir, "")
+}
+
+// OpenNamed opens the repository of the project in dir with the given name in .adr.yaml, the default one for the
+// empty name
+func OpenNamed(dir, name string) (*Repository, error) {
+	return OpenFS(storage.OS, dir, name)
+}
+
+// OpenFS opens the named repository of the project in dir of fsys, see OpenNamed. Dir is made absolute for the disk
+// only, so with other file systems it is as relative as dir, e.g. '.' for the root of a storage.Memory.
+func OpenFS(fsys storage.FS, dir, name string) (*Repository, error) {
+	if fsys == storage.OS {
+		var err error
+		if dir, err = filepath.Abs(dir); err != nil {
+			return nil, err
+		}
+	}
+	c, err := config.LoadConfig(fsys, dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		c = config.NewDefaultConfig()
+		c.WorkingDirectory, c.FS = dir, fsys
+	} else if err != nil {
+		return nil, err
+	}
+	r, a, err := c.Lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	return &Repository{Name: r.Name, Dir: project(dir, r.Path), fs: fsys, config: c, settings: a}, nil
+}
+
+// project returns p of the project in dir, p is relative to dir unless it is absolute
+func project(dir, p string) string {
+	if filepath.IsAbs(p) {
+		return p
+	}
+	return filepath.Join(dir, p)
+}
+
+// List returns every record of the repository, ordered by category prefix and number
+func (r *Repository) List() ([]*Record, error) {
+	if _, err := r.fs.Stat(r.Dir); errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	records, err := config.LoadRecords(r.fs, r.Dir)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]*Record, 0, len(records))
+	for _, cr := range records {
+		list = append(list, r.record(r.Name, r.Dir, r.settings, cr))
+	}
+	return list, nil
+}
+
+// Get returns the record ref refers to
+func (r *Repository) Get(ref Ref) (*Record, error) {
+	p, name, dir, a, err := r.locate(ref)
+	if err != nil {
+		return nil, err
+	}
+	return r.read(name, dir, a, p)
+}
+
+// Create writes a new record rendered from the templates of the repository, numbered next in its category, and
+// returns it. The directory of the repository is made if needed. The author is $ADR_AUTHOR, then git's user.name,
+// when the draft has none.
+func (r *Repository) Create(d Draft) (*Record, error) {
+	if strings.TrimSpace(d.Title) == "" {
+		return nil, errors.New("a record needs a title")
+	}
+	if err := r.fs.MkdirAll(r.Dir, fs.ModePerm); err != nil {
+		return nil, err
+	}
+	cs := r.changeset()
+	p, err := cs.New(r.settings, r.Dir, d.values(config.DefaultAuthor(r.config.WorkingDirectory), r.settings.DateLayout()))
+	var exists *config.ExistsError
+	if errors.As(err, &exists) {
+		return nil, &ExistsError{Path: exists.Path}
+	} else if err != nil {
+		return nil, err
+	}
+	if err = cs.FillSections(p, d.Sections); err != nil {
+		return nil, err
+	}
+	if err = cs.Apply(); err != nil {
+		return nil, err
+	}
+	return r.read(r.Name, r.Dir, r.settings, p)
+}
+
+// SetStatus replaces the status of the record ref refers to and returns the updated record. When the repository has
+// a review policy (adr.review), a record is only accepted once it has the approvals the policy asks for.
+func (r *Repository) SetStatus(ref Ref, to Status) (*Record, error) {
+	if strings.TrimSpace(string(to)) == "" {
+		return nil, &InvalidStatusError{Status: to}
+	}
+	p, name, dir, a, err := r.locate(ref)
+	if err != nil {
+		return nil, err
+	}
+	cs := r.changeset()
+	if err = cs.SetStatus(a, p, string(to)); err != nil {
+		return nil, err
+	}
+	if err = cs.Apply(); err != nil {
+		return nil, err
+	}
+	return r.read(name, dir, a, p)
+}
+
+// Link links two records, from gets a 'Links to' link with message and to one back with backMessage
+func (r *Repository) Link(from, to Ref, message, backMessage string) error {
+	lp, err := r.linkPair(from, to, message, backMessage)
+	if err != nil {
+		return err
+	}
+	cs := r.changeset()
+	if err = cs.Link(lp); err != nil {
+		return err
+	}
+	return cs.Apply()
+}
+
+// Supersede marks the record old as Superseded by the record by, old gets a 'Superseded by' link with message and
+// by a 'Supersedes' link with backMessage. The messages may be empty.
+func (r *Repository) Supersede(old, by Ref, message, backMessage string) error {
+	lp, err := r.linkPair(old, by, message, backMessage)
+	if err != nil {
+		return err
+	}
+	cs := r.changeset()
+	if err = cs.Supersede(lp); err != nil {
+		return err
+	}
+	return cs.Apply()
+}
+
+// changeset returns an empty changeset of the file system of the repository
+func (r *Repository) changeset() *config.Changeset {
+	cs := config.NewChangeset()
+	cs.FS = r.fs
+	return cs
+}
+
+// linkPair finds the records of a link
+func (r *Repository) linkPair(source, target Ref, message, backMessage string) (*config.LinkPair, error) {
+	sp, _, sdir, _, err := r.locate(source)
+	if err != nil {
+		return nil, err
+	}
+	tp, _, tdir, _, err := r.locate(target)
+	if err != nil {
+		return nil, err
+	}
+	return &config.LinkPair{
+		SourceNum:     source.Number,
+		TargetNum:     target.Number,
+		SourceMsg:     message,
+		BackMsg:       backMessage,
+		RepoDir:       sdir,
+		TargetRepoDir: tdir,
+		SourcePath:    sp,
+		TargetPath:    tp,
+	}, nil
+}
+
+// locate returns the path of the record ref refers to, with the name, directory and settings of its repository
+func (r *Repository) locate(ref Ref) (string, string, string, *config.ADR, error) {
+	name, dir, a := r.Name, r.Dir, r.settings
+	if ref.Repository != "" && ref.Repository != r.Name {
+		other, oa, err := r.config.Lookup(ref.Repository)
+		if err != nil {
+			return "", "", "", nil, &InvalidRefError{Ref: ref.String(), Err: err}
+		}
+		name, dir, a = other.Name, project(r.config.WorkingDirectory, other.Path), oa
+	}
+	cat, err := a.Category(ref.Category)
+	if err != nil {
+		return "", "", "", nil, &InvalidRefError{Ref: ref.String(), Err: err}
+	}
+	p, err := a.FindRecord(r.fs, dir, cat, ref.Number)
+	var missing *config.NotFoundError
+	if errors.As(err, &missing) || errors.Is(err, fs.ErrNotExist) {
+		return "", "", "", nil, &NotFoundError{Ref: ref}
+	} else if err != nil {
+		return "", "", "", nil, err
+	}
+	return p, name, dir, a, nil
+}
+
+// read parses the record at p of the named repository in dir
+func (r *Repository) read(name, dir string, a *config.ADR, p string) (*Record, error) {
+	b, err := r.fs.ReadFile(p)
+	if err != nil {
+		return nil, err
+	}
+	return r.record(name, dir, a, config.ParseRecord(p, b)), nil
+}
+
+// record converts a parsed record of the named repository in dir
+func (r *Repository) record(name, dir string, a *config.ADR, cr *config.Record) *Record {
+	rec := &Record{
+		Ref:      Ref{Repository: name, Number: cr.Number},
+		ID:       cr.ID,
+		Path:     cr.Path,
+		Title:    cr.Title,
+		Date:     cr.Date,
+		Status:   Status(cr.Status),
+		Metadata: cr.Metadata,
+	}
+	if name == r.Name {
+		rec.Ref.Repository = ""
+	}
+	if cat := a.CategoryOf(dir, cr.Path); cat != nil {
+		rec.Ref.Category = cat.Name
+	}
+	for _, l := range cr.Links {
+		rec.Links = append(rec.Links, Link{Relation: l.Rel, Target: l.Target, Message: l.Message})
+	}
+	for _, v := range cr.Reviews {
+		rec.Reviews = append(rec.Reviews, Review{Reviewer: v.Reviewer, State: v.State, Date: v.Date, Comment: v.Comment})
+	}
+	for _, s := range cr.Sections {
+		rec.Sections = append(rec.Sections, Section{Name: s.Name, Content: s.Content})
+	}
+	return rec
+}
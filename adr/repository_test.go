@@ -0,0 +1,231 @@
+package adr
+
+import (
+	"errors"
+	"github.com/fleetingclarity/adr/storage"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newProject writes a .adr.yaml to a new directory and returns the directory
+func newProject(t *testing.T, yaml string) string {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, ".adr.yaml"), []byte(yaml), 0644))
+	return dir
+}
+
+func Test_RepositoryLifecycle(t *testing.T) {
+	t.Setenv("ADR_AUTHOR", "carol")
+	dir := newProject(t, "repository:\n  path: docs/decisions\n")
+	repo, err := Open(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "docs", "decisions"), repo.Dir)
+
+	records, err := repo.List()
+	assert.NoError(t, err)
+	assert.Empty(t, records)
+
+	first, err := repo.Create(Draft{Title: "Use Postgres", Date: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		Deciders: []string{"alice", "bob"}, Sections: map[string]string{"Context": "We need a database."}})
+	assert.NoError(t, err)
+	assert.Equal(t, Ref{Number: 1}, first.Ref)
+	assert.Equal(t, "Use Postgres", first.Title)
+	assert.Equal(t, "2024-03-01", first.Date)
+	assert.Equal(t, Proposed, first.Status)
+	assert.Equal(t, filepath.Join(repo.Dir, "001-use-postgres.md"), first.Path)
+	assert.Equal(t, "carol", first.Metadata["author"])
+	assert.Equal(t, "alice, bob", first.Metadata["deciders"])
+	context, ok := first.Section("Context")
+	assert.True(t, ok)
+	assert.Equal(t, "We need a database.", strings.TrimSpace(context))
+
+	second, err := repo.Create(Draft{Title: "Use CockroachDB"})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, second.Ref.Number)
+
+	accepted, err := repo.SetStatus(first.Ref, Accepted)
+	assert.NoError(t, err)
+	assert.Equal(t, Accepted, accepted.Status)
+
+	assert.NoError(t, repo.Link(second.Ref, first.Ref, "Builds on", "Built on by"))
+	assert.NoError(t, repo.Supersede(first.Ref, second.Ref, "", "Replaces postgres"))
+
+	got, err := repo.Get(first.Ref)
+	assert.NoError(t, err)
+	assert.True(t, got.Status.Is(Superseded))
+	assert.Equal(t, []Link{{Relation: "Superseded by", Target: "002-use-cockroachdb.md"}}, got.Links)
+
+	records, err = repo.List()
+	assert.NoError(t, err)
+	if assert.Len(t, records, 2) {
+		assert.Equal(t, "Use Postgres", records[0].Title)
+		if assert.Len(t, records[1].Links, 2) {
+			assert.Equal(t, "001-use-postgres.md", records[1].Links[0].Target)
+			assert.Equal(t, Link{Relation: "Supersedes", Target: "001-use-postgres.md", Message: "Replaces postgres"}, records[1].Links[1])
+		}
+	}
+}
+
+func Test_DraftDateInTheDateFormat(t *testing.T) {
+	repo, err := Open(newProject(t, "adr:\n  dateformat: '02.01.2006 15:04'\n  timezone: UTC\n"))
+	assert.NoError(t, err)
+	r, err := repo.Create(Draft{Title: "Use Postgres", Author: "carol", Date: time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)})
+	assert.NoError(t, err)
+	assert.Equal(t, "01.03.2024 09:30", r.Date)
+}
+
+func Test_RepositoryErrors(t *testing.T) {
+	dir := newProject(t, `repository:
+  path: docs/decisions
+adr:
+  categories:
+    - name: security
+      prefix: SEC
+`)
+	repo, err := Open(dir)
+	assert.NoError(t, err)
+
+	_, err = repo.Get(Ref{Number: 4})
+	assert.True(t, errors.Is(err, ErrNotFound))
+	var missing *NotFoundError
+	if assert.True(t, errors.As(err, &missing)) {
+		assert.Equal(t, Ref{Number: 4}, missing.Ref)
+	}
+
+	r, err := repo.Create(Draft{Title: "Use mTLS", Category: "SEC"})
+	assert.NoError(t, err)
+	assert.Equal(t, Ref{Category: "security", Number: 1}, r.Ref)
+	assert.Equal(t, "SEC-001", r.ID)
+	_, err = repo.Get(Ref{Number: 1})
+	assert.True(t, errors.Is(err, ErrNotFound))
+	_, err = repo.Get(r.Ref)
+	assert.NoError(t, err)
+
+	_, err = repo.Get(Ref{Category: "finance", Number: 1})
+	var invalid *InvalidRefError
+	assert.True(t, errors.As(err, &invalid))
+	_, err = repo.Get(Ref{Repository: "billing", Number: 1})
+	assert.True(t, errors.As(err, &invalid))
+	_, err = ParseRef("twelve")
+	assert.True(t, errors.As(err, &invalid))
+
+	_, err = repo.SetStatus(r.Ref, " ")
+	var status *InvalidStatusError
+	assert.True(t, errors.As(err, &status))
+
+	unnumbered, err := Open(newProject(t, "adr:\n  titletemplate: '{{ .Slug }}.md'\n"))
+	assert.NoError(t, err)
+	_, err = unnumbered.Create(Draft{Title: "Use vault"})
+	assert.NoError(t, err)
+	_, err = unnumbered.Create(Draft{Title: "Use vault"})
+	assert.True(t, errors.Is(err, ErrExists))
+}
+
+func Test_RepositoriesByName(t *testing.T) {
+	dir := newProject(t, `repositories:
+  - name: platform
+    path: platform/decisions
+  - name: billing
+    path: billing/decisions
+    adr:
+      metadata: frontmatter
+`)
+	platform, err := Open(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, "platform", platform.Name)
+	billing, err := OpenNamed(dir, "billing")
+	assert.NoError(t, err)
+	_, err = OpenNamed(dir, "payroll")
+	assert.Error(t, err)
+
+	p, err := platform.Create(Draft{Title: "Run on Kubernetes"})
+	assert.NoError(t, err)
+	b, err := billing.Create(Draft{Title: "Bill monthly"})
+	assert.NoError(t, err)
+
+	assert.NoError(t, billing.Link(b.Ref, Ref{Repository: "platform", Number: p.Ref.Number}, "Runs on", "Hosts billing"))
+	got, err := platform.Get(Ref{Repository: "billing", Number: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, Ref{Repository: "billing", Number: 1}, got.Ref)
+	assert.Equal(t, []Link{{Relation: "Links to", Target: "../../platform/decisions/001-run-on-kubernetes.md", Message: "Runs on"}}, got.Links)
+}
+
+func Test_ParseRef(t *testing.T) {
+	for _, s := range []string{"12", "security/12", "billing:12", "billing:SEC/12"} {
+		ref, err := ParseRef(s)
+		assert.NoError(t, err)
+		assert.Equal(t, s, ref.String())
+	}
+	ref, err := ParseRef("SEC-12")
+	assert.NoError(t, err)
+	assert.Equal(t, Ref{Category: "SEC", Number: 12}, ref)
+}
+
+func Test_RepositoryInMemory(t *testing.T) {
+	m := storage.NewMemory()
+	assert.NoError(t, m.WriteFile(".adr.yaml", []byte("repository:\n  path: decisions\nadr:\n  metadata: frontmatter\n"), 0644))
+	repo, err := OpenFS(m, ".", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "decisions", repo.Dir)
+
+	first, err := repo.Create(Draft{Title: "Use Postgres", Author: "carol"})
+	assert.NoError(t, err)
+	assert.Equal(t, "decisions/001-use-postgres.md", first.Path)
+	second, err := repo.Create(Draft{Title: "Use CockroachDB", Author: "carol"})
+	assert.NoError(t, err)
+	assert.NoError(t, repo.Supersede(first.Ref, second.Ref, "", ""))
+
+	records, err := repo.List()
+	assert.NoError(t, err)
+	if assert.Len(t, records, 2) {
+		assert.Equal(t, Superseded, records[0].Status)
+		assert.Equal(t, []Link{{Relation: "Superseded by", Target: "002-use-cockroachdb.md"}}, records[0].Links)
+	}
+	b, err := m.ReadFile("decisions/002-use-cockroachdb.md")
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), "rel: Supersedes")
+	_, err = os.Stat("decisions")
+	assert.True(t, errors.Is(err, os.ErrNotExist), "nothing is written to the disk")
+}
+
+func Test_RepositoryAtCommit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	t.Setenv("ADR_AUTHOR", "carol")
+	dir := newProject(t, "repository:\n  path: docs/decisions\n")
+	repo, err := Open(dir)
+	assert.NoError(t, err)
+	r, err := repo.Create(Draft{Title: "Use Postgres"})
+	assert.NoError(t, err)
+	for _, args := range [][]string{
+		{"init", "--quiet"},
+		{"config", "user.name", "Test Author"},
+		{"config", "user.email", "author@example.com"},
+		{"config", "commit.gpgsign", "false"},
+		{"add", "."},
+		{"commit", "--quiet", "-m", "Propose postgres"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		assert.NoError(t, err, string(out))
+	}
+	_, err = repo.SetStatus(r.Ref, Accepted)
+	assert.NoError(t, err)
+
+	tree, err := storage.NewGitTree(dir, "HEAD")
+	assert.NoError(t, err)
+	committed, err := OpenFS(tree, ".", "")
+	assert.NoError(t, err)
+	got, err := committed.Get(r.Ref)
+	assert.NoError(t, err)
+	assert.Equal(t, Proposed, got.Status, "the record as committed, before it was accepted")
+	_, err = committed.SetStatus(r.Ref, Accepted)
+	assert.True(t, errors.Is(err, storage.ErrReadOnly))
+}
@@ -0,0 +1,49 @@
+/*
+Copyright © 2022 fleetingclarity <72276886+fleetingclarity@users.noreply.github.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	conf "github.com/fleetingclarity/adr/config"
+	"github.com/spf13/cobra"
+)
+
+// acceptCmd represents the accept command
+var acceptCmd = &cobra.Command{
+	Use:   "accept <number>",
+	Args:  cobra.ExactArgs(1),
+	Short: "Accept an ADR once it has the required approvals",
+	Long: `Set the status of a record to Accepted, refusing until the approvals recorded with
+'adr review approve' meet the review policy in .adr.yaml:
+
+  adr:
+    review:
+      approvals: 2        # reviewers who must approve, 1 by default
+      allrequested: true  # every requested reviewer must also approve`,
+	Run: func(cmd *cobra.Command, args []string) {
+		p, err := locate(args[0])
+		cobra.CheckErr(err)
+		cs := conf.NewChangeset()
+		cobra.CheckErr(cs.Accept(config.ADR, p))
+		cobra.CheckErr(apply(cmd, cs, p))
+		if !dryRun {
+			cmd.Printf("%s status updated to Accepted\n", p)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(acceptCmd)
+}
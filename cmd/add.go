@@ -20,34 +20,120 @@ import (
 	conf "github.com/fleetingclarity/adr/config"
 	"github.com/spf13/cobra"
 	"path"
+	"strings"
+)
+
+var (
+	setValues []string
+	author    string
+	deciders  []string
+	consulted []string
+	informed  []string
+	interact  bool
+	edit      bool
+	date      string
+	category  string
+	revisitBy string
+	expires   string
 )
 
 // addCmd represents the add command
 var addCmd = &cobra.Command{
 	Use:     "add \"Some title\"",
 	Aliases: []string{"new", "create"},
-	Args:    cobra.ExactArgs(1),
-	Short:   "Add a new record to the ADR repository",
+	Args: func(cmd *cobra.Command, args []string) error {
+		if interact {
+			return cobra.MaximumNArgs(1)(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
+	Short: "Add a new record to the ADR repository",
 	Long: `Create a new ADR in the repository. The only argument to the Add function is the Title of
-the new ADR. The Title will be used to name the file and as the heading within the markdown of the
-file. Capital letters, symbols, and spaces will be converted to single dashes (-).
+the new ADR. The Title is used as typed in the heading within the markdown of the file, and as a
+slug to name the file: lower case words with symbols and spaces converted to single dashes (-),
+following the adr.slug rules of .adr.yaml.
 
 Example usage: adr add "Some title"
-Results in: A file in your repo appropriately numbered like 'NNN-some-title.md'`,
+Results in: A file in your repo appropriately numbered like 'NNN-some-title.md'
+
+Fields declared under adr.fields in .adr.yaml can be given with --set, e.g.
+adr add "Some title" --set tags=billing,data --set cost-tier=low
+
+The people involved are recorded with --decider, --consulted and --informed (each repeatable
+or comma separated). The author defaults to $ADR_AUTHOR, then git's user.name.
+adr add "Some title" --decider alice --decider bob --consulted team-db
+
+With -i the title (when not given), declared fields, participants and the content of each
+template section are prompted for. Answers are read line by line from stdin, a section ends
+with a line containing only '.', so the prompts can be scripted:
+printf 'We need a database.\n.\nPostgres.\n.\n\n' | adr add -i "Use postgres" --author me \
+  --decider alice --consulted team-db --informed ops
+
+Records are dated today in the adr.dateformat layout (default 2006-01-02) and adr.timezone
+of .adr.yaml. Use --date 2024-03-01 to backdate records when importing older decisions.
+
+Records can be grouped into the categories declared under adr.categories in .adr.yaml, each
+kept in its own directory or named with its own prefix and numbered in its own sequence:
+adr add "Use mTLS between services" --category security    # e.g. SEC-007-use-mtls-between-services.md
+
+With --edit the new record is opened in $VISUAL or $EDITOR and checked once the editor exits.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		m := make(map[string]string)
-		m["Title"] = args[0]
+		cobra.CheckErr(config.ParseSet(setValues, m))
+		if len(args) > 0 {
+			m["Title"] = args[0]
+		}
+		m["Date"] = date
+		m["Category"] = category
+		m["ReviewBy"] = revisitBy
+		m["Expires"] = expires
+		if author == "" {
+			author = conf.DefaultAuthor(config.WorkingDirectory)
+		}
+		m[conf.RoleAuthor] = author
+		m[conf.RoleDeciders] = strings.Join(deciders, ",")
+		m[conf.RoleConsulted] = strings.Join(consulted, ",")
+		m[conf.RoleInformed] = strings.Join(informed, ",")
+		var sections map[string]string
+		if interact {
+			var err error
+			sections, err = config.Interview(cmd.InOrStdin(), cmd.OutOrStdout(), m)
+			cobra.CheckErr(err)
+		}
 		if verbose {
-			fmt.Printf("Your title '%s' will be converted to '%s'\n", args[0], conf.Sanitize(args[0]))
+			fmt.Printf("Your title '%s' will be named '%s'\n", m["Title"], config.Slugify(m["Title"]))
+		}
+		cs := conf.NewChangeset()
+		p, err := cs.New(config.ADR, config.Repository.Path, m)
+		cobra.CheckErr(err)
+		cobra.CheckErr(cs.FillSections(p, sections))
+		cobra.CheckErr(apply(cmd, cs, p))
+		if dryRun {
+			return
 		}
-		cobra.CheckErr(config.New(config.Repository.Path, m))
-		fmt.Printf("Success! Edit your new ADR at %s\n", path.Join(config.WorkingDirectory, config.Repository.Path))
+		if edit {
+			cobra.CheckErr(editRecord(cmd, p))
+			return
+		}
+		fmt.Printf("Success! Edit your new ADR at %s\n", path.Join(config.WorkingDirectory, p))
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(addCmd)
 
+	addCmd.Flags().StringArrayVar(&setValues, "set", nil, "Set a declared field, as key=value (repeatable)")
+	addCmd.Flags().StringVar(&author, "author", "", "The author of the record (default $ADR_AUTHOR or git's user.name)")
+	addCmd.Flags().StringArrayVar(&deciders, "decider", nil, "Someone who made the decision (repeatable)")
+	addCmd.Flags().StringArrayVar(&consulted, "consulted", nil, "Someone whose opinion was sought (repeatable)")
+	addCmd.Flags().StringArrayVar(&informed, "informed", nil, "Someone kept up to date on the decision (repeatable)")
+	addCmd.Flags().BoolVarP(&interact, "interactive", "i", false, "Prompt for the title, fields, participants and section content")
+	addCmd.Flags().BoolVarP(&edit, "edit", "e", false, "Open the new record in $VISUAL or $EDITOR, then lint it")
+	addCmd.Flags().StringVar(&date, "date", "", "Date the record, e.g. 2024-03-01, instead of today")
+	addCmd.Flags().StringVarP(&category, "category", "c", "", "Add the record to a category declared under adr.categories, numbered in its own sequence")
+	addCmd.Flags().StringVar(&revisitBy, "review-by", "", "Revisit the decision by a date, or after a period like 90d, 6m or 1y (default adr.revisit.reviewafter)")
+	addCmd.Flags().StringVar(&expires, "expires", "", "Expire the decision on a date, or after a period like 90d, 6m or 1y")
+
 	// Here you will define your flags and configuration settings.
 
 	// Cobra supports Persistent Flags which will work for this command
@@ -0,0 +1,101 @@
+/*
+Copyright © 2022 fleetingclarity <72276886+fleetingclarity@users.noreply.github.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	conf "github.com/fleetingclarity/adr/config"
+	"github.com/spf13/cobra"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+)
+
+var (
+	aggregateFormat string
+	aggregateOutput string
+)
+
+// aggregateCmd represents the aggregate command
+var aggregateCmd = &cobra.Command{
+	Use:   "aggregate <dir>...",
+	Args:  cobra.MinimumNArgs(1),
+	Short: "Combine the decision logs of many checkouts into one catalogue",
+	Long: `Walk the given directories for projects with a .adr.yaml, or an adr-tools .adr-dir, and
+combine the records of every repository they hold into one catalogue. Each project's
+records are read with its own configuration.
+
+Records are identified by the project they came from, e.g. billing:007, or billing/ledger:007
+for a project with several repositories. Links between records, including links from one
+checkout to another, are resolved to these IDs.
+
+Use --format json for tooling, or --format markdown --output docs/index.md to publish an
+index that links to every record.`,
+	Example: `  adr aggregate ~/src
+  adr aggregate ~/src/billing ~/src/platform --format markdown --output catalogue.md`,
+	Run: func(cmd *cobra.Command, args []string) {
+		sources, err := conf.Discover(config.FS, args...)
+		cobra.CheckErr(err)
+		if len(sources) == 0 {
+			cobra.CheckErr(fmt.Errorf("no ADR repositories found in %s", strings.Join(args, ", ")))
+		}
+		cat, err := conf.Aggregate(config.FS, sources)
+		cobra.CheckErr(err)
+		w, dir := cmd.OutOrStdout(), "."
+		if aggregateOutput != "" {
+			f, err := os.Create(aggregateOutput)
+			cobra.CheckErr(err)
+			defer f.Close()
+			w, dir = f, filepath.Dir(aggregateOutput)
+		}
+		switch aggregateFormat {
+		case "text":
+			cobra.CheckErr(writeCatalogue(w, cat))
+		case "json":
+			cobra.CheckErr(writeJSON(w, cat))
+		case "markdown":
+			cobra.CheckErr(cat.WriteMarkdown(w, dir))
+		default:
+			cobra.CheckErr(fmt.Errorf("unknown format '%s', expected text, json or markdown", aggregateFormat))
+		}
+	},
+}
+
+// writeCatalogue writes one aligned row per record of the catalogue with the IDs of the records it links to
+func writeCatalogue(w io.Writer, cat *conf.Catalogue) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, e := range cat.Entries {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s", e.ID, e.Record.Status, e.Record.Date, e.Record.Title)
+		for _, l := range e.Links {
+			target := l.ID
+			if target == "" {
+				target = l.Target
+			}
+			fmt.Fprintf(tw, "\t%s %s", l.Rel, target)
+		}
+		fmt.Fprintln(tw)
+	}
+	return tw.Flush()
+}
+
+func init() {
+	rootCmd.AddCommand(aggregateCmd)
+
+	aggregateCmd.Flags().StringVarP(&aggregateFormat, "format", "f", "text", "Output format: text, json or markdown")
+	aggregateCmd.Flags().StringVarP(&aggregateOutput, "output", "o", "", "Write the catalogue to a file instead of standard output")
+}
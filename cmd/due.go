@@ -0,0 +1,117 @@
+/*
+Copyright © 2022 fleetingclarity <72276886+fleetingclarity@users.noreply.github.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	conf "github.com/fleetingclarity/adr/config"
+	"github.com/spf13/cobra"
+	"io"
+	"text/tabwriter"
+)
+
+var (
+	dueFormat     string
+	dueStaleAfter int
+	dueAsOf       string
+)
+
+// dueCmd represents the due command
+var dueCmd = &cobra.Command{
+	Use:   "due",
+	Args:  cobra.NoArgs,
+	Short: "List records due to be revisited",
+	Long: `List the records that need attention: those past their Review-By date, those past their
+Expires date, and those still Proposed longer than adr.revisit.staleafter days (30 by default).
+Superseded, deprecated and rejected records are never due.
+
+Revisit dates are set with 'adr add --review-by' and '--expires', or for every new record
+with adr.revisit.reviewafter in .adr.yaml.
+
+Exits with an error when any record is due, so a scheduled CI job fails until they are dealt
+with.`,
+	Example: `  adr due
+  adr due --stale-after 14 --format json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		due, err := dueRecords()
+		cobra.CheckErr(err)
+		switch dueFormat {
+		case "text":
+			cobra.CheckErr(writeDue(cmd.OutOrStdout(), due))
+		case "json":
+			cobra.CheckErr(writeJSON(cmd.OutOrStdout(), due))
+		default:
+			cobra.CheckErr(fmt.Errorf("unknown format '%s', expected text or json", dueFormat))
+		}
+		if len(due) > 0 {
+			cobra.CheckErr(fmt.Errorf("%d record(s) due", len(due)))
+		}
+	},
+}
+
+// dueRecords returns the records of the repository that are due on the --as-of day, today by default
+func dueRecords() ([]conf.Due, error) {
+	if dueStaleAfter > 0 {
+		p := conf.RevisitPolicy{}
+		if config.Revisit != nil {
+			p = *config.Revisit
+		}
+		p.StaleAfter = dueStaleAfter
+		config.Revisit = &p
+	}
+	now, err := config.RecordTime(dueAsOf)
+	if err != nil {
+		return nil, err
+	}
+	records, err := conf.LoadRecords(config.FS, config.Repository.Path)
+	if err != nil {
+		return nil, err
+	}
+	return config.Due(records, now), nil
+}
+
+// writeDue writes one aligned row per due record
+func writeDue(w io.Writer, due []conf.Due) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, d := range due {
+		fmt.Fprintf(tw, "%s\t%s\t%s since %s (%s)\t%s\n", d.Record.ID, d.Record.Status, d.Reason, d.Since, days(d.Days), d.Record.Title)
+	}
+	return tw.Flush()
+}
+
+// days formats a number of days ago
+func days(n int) string {
+	switch n {
+	case 0:
+		return "today"
+	case 1:
+		return "1 day"
+	}
+	return fmt.Sprintf("%d days", n)
+}
+
+// addDueFlags adds the flags that choose which records are due
+func addDueFlags(cmd *cobra.Command) {
+	cmd.Flags().IntVar(&dueStaleAfter, "stale-after", 0, "Days a record may stay Proposed (default adr.revisit.staleafter or 30)")
+	cmd.Flags().StringVar(&dueAsOf, "as-of", "", "Check as of a date, e.g. 2024-03-01, instead of today")
+}
+
+func init() {
+	rootCmd.AddCommand(dueCmd)
+
+	dueCmd.Flags().StringVarP(&dueFormat, "format", "f", "text", "Output format: text or json")
+	addDueFlags(dueCmd)
+}
@@ -0,0 +1,61 @@
+/*
+Copyright © 2022 fleetingclarity <72276886+fleetingclarity@users.noreply.github.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	conf "github.com/fleetingclarity/adr/config"
+	"github.com/spf13/cobra"
+)
+
+// editCmd represents the edit command
+var editCmd = &cobra.Command{
+	Use:   "edit <number>",
+	Args:  cobra.ExactArgs(1),
+	Short: "Open a record in your editor and check it afterwards",
+	Long: `Open the numbered record in $VISUAL, or $EDITOR when that is not set. When the editor exits
+the record is linted and any problems are reported, e.g. sections that still hold the
+template text. Exits with an error when the record has errors.
+
+Example usage: adr edit 4`,
+	Run: func(cmd *cobra.Command, args []string) {
+		p, err := locate(args[0])
+		cobra.CheckErr(err)
+		cobra.CheckErr(editRecord(cmd, p))
+	},
+}
+
+// editRecord opens p in the user's editor, then lints the same file and reports what was found
+func editRecord(cmd *cobra.Command, p string) error {
+	if err := conf.Edit(p, cmd.InOrStdin(), cmd.OutOrStdout(), cmd.ErrOrStderr()); err != nil {
+		return err
+	}
+	findings, err := config.LintFile(config.FS, p)
+	if err != nil {
+		return err
+	}
+	for _, f := range findings {
+		cmd.Println(f)
+	}
+	if conf.HasErrors(findings) {
+		return fmt.Errorf("%d error(s) found in %s", conf.CountErrors(findings), p)
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(editCmd)
+}
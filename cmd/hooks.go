@@ -0,0 +1,102 @@
+/*
+Copyright © 2022 fleetingclarity <72276886+fleetingclarity@users.noreply.github.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	conf "github.com/fleetingclarity/adr/config"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+// hooksCmd represents the hooks command
+var hooksCmd = &cobra.Command{
+	Use:   "hooks",
+	Short: "Install git hooks that check ADRs and commit trailers",
+	Long: `Install git hooks that check records as they are committed:
+
+  pre-commit  lints the staged records and blocks edits to the sections of Accepted
+              records, which are superseded rather than rewritten
+  commit-msg  checks that 'Decision: ADR-012' trailers refer to existing records that
+              were not rejected
+
+Hooks already in place are kept and run before adr's.`,
+}
+
+// hooksInstallCmd represents the hooks install command
+var hooksInstallCmd = &cobra.Command{
+	Use:   "install",
+	Args:  cobra.NoArgs,
+	Short: "Install the pre-commit and commit-msg hooks",
+	Run: func(cmd *cobra.Command, args []string) {
+		installed, err := config.InstallHooks()
+		for _, p := range installed {
+			cmd.Printf("Installed %s\n", p)
+		}
+		cobra.CheckErr(err)
+	},
+}
+
+// hooksUninstallCmd represents the hooks uninstall command
+var hooksUninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Args:  cobra.NoArgs,
+	Short: "Remove the hooks, restoring the ones they chained to",
+	Run: func(cmd *cobra.Command, args []string) {
+		removed, err := config.UninstallHooks()
+		for _, p := range removed {
+			cmd.Printf("Removed %s\n", p)
+		}
+		cobra.CheckErr(err)
+	},
+}
+
+// hooksRunCmd represents the hooks run command, which the installed hooks call
+var hooksRunCmd = &cobra.Command{
+	Use:    "run <hook> [args...]",
+	Args:   cobra.MinimumNArgs(1),
+	Hidden: true,
+	Short:  "Run one of the hooks",
+	Run: func(cmd *cobra.Command, args []string) {
+		switch args[0] {
+		case "pre-commit":
+			findings, err := config.PreCommit()
+			cobra.CheckErr(err)
+			for _, f := range findings {
+				cmd.PrintErrln(f)
+			}
+			if conf.HasErrors(findings) {
+				cobra.CheckErr(fmt.Errorf("%d error(s) found in the staged records", conf.CountErrors(findings)))
+			}
+		case "commit-msg":
+			if len(args) < 2 {
+				cobra.CheckErr(fmt.Errorf("the commit-msg hook needs the message file"))
+			}
+			msg, err := os.ReadFile(args[1])
+			cobra.CheckErr(err)
+			cobra.CheckErr(config.CheckCommitMessage(msg))
+		default:
+			cobra.CheckErr(fmt.Errorf("unknown hook '%s'", args[0]))
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(hooksCmd)
+	hooksCmd.AddCommand(hooksInstallCmd)
+	hooksCmd.AddCommand(hooksUninstallCmd)
+	hooksCmd.AddCommand(hooksRunCmd)
+}
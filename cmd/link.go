@@ -18,7 +18,6 @@ package cmd
 import (
 	conf "github.com/fleetingclarity/adr/config"
 	"github.com/spf13/cobra"
-	"strconv"
 )
 
 // linkCmd represents the link command
@@ -31,21 +30,16 @@ altered but not fully superseded.
 
 Expected usage: adr link <linker#> <link-message> <linked#> <back-link-message>
 
-Example: adr link 182 "Amends some important thing" 10 "Important thing is amended"`,
+Example: adr link 182 "Amends some important thing" 10 "Important thing is amended"
+
+Records in other repositories named in .adr.yaml are given as repository:number, e.g.
+adr link billing:12 "Follows the platform decision" platform:4 "Followed by billing"`,
 	Run: func(cmd *cobra.Command, args []string) {
-		lnum, err := strconv.Atoi(args[0])
-		cobra.CheckErr(err)
-		rnum, err := strconv.Atoi(args[2])
-		cobra.CheckErr(err)
-		lp := &conf.LinkPair{
-			SourceNum: lnum,
-			TargetNum: rnum,
-			SourceMsg: args[1],
-			BackMsg:   args[3],
-			RepoDir:   config.Repository.Path,
-		}
-		err = conf.Link(lp)
+		lp, sp, err := linkPair(args[0], args[1], args[2], args[3])
 		cobra.CheckErr(err)
+		cs := conf.NewChangeset()
+		cobra.CheckErr(cs.Link(lp))
+		cobra.CheckErr(apply(cmd, cs, sp))
 	},
 }
 
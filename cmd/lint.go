@@ -0,0 +1,124 @@
+/*
+Copyright © 2022 fleetingclarity <72276886+fleetingclarity@users.noreply.github.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	conf "github.com/fleetingclarity/adr/config"
+	"github.com/spf13/cobra"
+	"path/filepath"
+)
+
+var (
+	lintFormat       string
+	lintChangedSince string
+)
+
+// lintCmd represents the lint command
+var lintCmd = &cobra.Command{
+	Use:     "lint [number...]",
+	Aliases: []string{"check", "validate"},
+	Short:   "Check that records match the ADR format",
+	Long: `Check every record in the ADR repository, or only the numbered ones, against the format:
+each must have a heading numbered like its file, a Status, every section of the body
+template, and valid values for the fields declared under adr.fields in .adr.yaml.
+Sections that still hold the template text are reported as warnings.
+
+For CI, --format writes the findings as json, sarif (for code scanning), junit (for test
+reports) or github (annotations on the pull request), and --changed-since only checks the
+records changed since the branch left a git ref.
+
+Exits with an error when any error is found.`,
+	Example: `  adr lint
+  adr lint 12 SEC-3
+  adr lint --changed-since origin/main --format github`,
+	Run: func(cmd *cobra.Command, args []string) {
+		var paths []string
+		for _, a := range args {
+			p, err := locate(a)
+			cobra.CheckErr(err)
+			paths = append(paths, p)
+		}
+		if len(args) == 0 {
+			records, err := conf.LoadRecords(config.FS, config.Repository.Path)
+			cobra.CheckErr(err)
+			for _, r := range records {
+				paths = append(paths, r.Path)
+			}
+		}
+		if lintChangedSince != "" {
+			changed, err := config.ChangedSince(lintChangedSince)
+			cobra.CheckErr(err)
+			paths = onlyChanged(paths, changed)
+		}
+		var findings []conf.Finding
+		for _, p := range paths {
+			f, err := config.LintFile(config.FS, p)
+			cobra.CheckErr(err)
+			findings = append(findings, f...)
+		}
+		cobra.CheckErr(writeFindings(cmd, lintFormat, paths, findings))
+		if conf.HasErrors(findings) {
+			cobra.CheckErr(fmt.Errorf("%d error(s) found", conf.CountErrors(findings)))
+		}
+	},
+}
+
+// writeFindings writes findings in the given format, paths are the records that were checked
+func writeFindings(cmd *cobra.Command, format string, paths []string, findings []conf.Finding) error {
+	w := cmd.OutOrStdout()
+	switch format {
+	case "text":
+		for _, f := range findings {
+			cmd.Println(f)
+		}
+		return nil
+	case "json":
+		if findings == nil {
+			findings = []conf.Finding{}
+		}
+		return writeJSON(w, findings)
+	case "sarif":
+		return conf.WriteSARIF(w, findings)
+	case "junit":
+		return conf.WriteJUnit(w, paths, findings)
+	case "github":
+		return conf.WriteGitHub(w, findings)
+	}
+	return fmt.Errorf("unknown format '%s', expected text, json, sarif, junit or github", format)
+}
+
+// onlyChanged returns the paths that are among the changed ones
+func onlyChanged(paths, changed []string) []string {
+	set := make(map[string]bool)
+	for _, c := range changed {
+		set[filepath.Clean(c)] = true
+	}
+	var kept []string
+	for _, p := range paths {
+		if set[filepath.Clean(p)] {
+			kept = append(kept, p)
+		}
+	}
+	return kept
+}
+
+func init() {
+	rootCmd.AddCommand(lintCmd)
+
+	lintCmd.Flags().StringVarP(&lintFormat, "format", "f", "text", "Output format: text, json, sarif, junit or github")
+	lintCmd.Flags().StringVar(&lintChangedSince, "changed-since", "", "Only check records changed since the branch left this git ref, e.g. origin/main")
+}
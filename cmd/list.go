@@ -0,0 +1,150 @@
+/*
+Copyright © 2022 fleetingclarity <72276886+fleetingclarity@users.noreply.github.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	conf "github.com/fleetingclarity/adr/config"
+	"github.com/spf13/cobra"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+var (
+	listFormat    string
+	listGit       bool
+	listWhere     []string
+	listDeciders  []string
+	listConsulted []string
+	listInformed  []string
+	listCategory  string
+)
+
+// listCmd represents the list command
+var listCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Args:    cobra.NoArgs,
+	Short:   "List the records in the ADR repository",
+	Long: `List every record in the ADR repository with its number, status, date and title.
+
+Use --git to enrich each record from the git log of its file: the original author, the
+creating commit, the last modified date and the commit of every status change.
+Use --format json to export the records for other tools.
+Use --where to filter on status, a participant role or a declared field, e.g. --where tags=billing.
+Use --decider, --consulted or --informed to see who owns which decisions, e.g. --decider alice.
+Use --category to list the records of one category.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		records, err := conf.LoadRecords(config.FS, config.Repository.Path)
+		cobra.CheckErr(err)
+		where := listWhere
+		where = append(where, roleConditions(conf.RoleDeciders, listDeciders)...)
+		where = append(where, roleConditions(conf.RoleConsulted, listConsulted)...)
+		where = append(where, roleConditions(conf.RoleInformed, listInformed)...)
+		records, err = filterRecords(records, where)
+		cobra.CheckErr(err)
+		if listCategory != "" {
+			cat, err := config.Category(listCategory)
+			cobra.CheckErr(err)
+			var inCategory []*conf.Record
+			for _, r := range records {
+				if config.CategoryOf(config.Repository.Path, r.Path) == cat {
+					inCategory = append(inCategory, r)
+				}
+			}
+			records = inCategory
+		}
+		if listGit {
+			for _, r := range records {
+				r.History, err = config.History(r.Path)
+				cobra.CheckErr(err)
+			}
+		}
+		switch listFormat {
+		case "json":
+			cobra.CheckErr(writeJSON(cmd.OutOrStdout(), records))
+		case "text":
+			cobra.CheckErr(writeRecordTable(cmd.OutOrStdout(), records))
+		default:
+			cobra.CheckErr(fmt.Errorf("unknown format '%s', expected text or json", listFormat))
+		}
+	},
+}
+
+// filterRecords keeps the records matching every 'field=value' condition, conditions on the same field are ORed
+func filterRecords(records []*conf.Record, where []string) ([]*conf.Record, error) {
+	q := &conf.Query{Fields: make(map[string][]string)}
+	for _, w := range where {
+		key, value, found := strings.Cut(w, "=")
+		if !found {
+			return nil, fmt.Errorf("expected field=value, not '%s'", w)
+		}
+		if strings.EqualFold(key, "status") {
+			q.Statuses = append(q.Statuses, value)
+		} else if conf.IsParticipantRole(key) {
+			role := strings.ToLower(key)
+			q.Fields[role] = append(q.Fields[role], value)
+		} else if f := config.ADR.Field(key); f != nil {
+			q.Fields[f.Name] = append(q.Fields[f.Name], value)
+		} else {
+			return nil, fmt.Errorf("unknown field '%s', declare it under adr.fields in .adr.yaml", key)
+		}
+	}
+	return q.Filter(records), nil
+}
+
+// roleConditions turns the people given for a participant role into filterRecords conditions
+func roleConditions(role string, people []string) []string {
+	var conditions []string
+	for _, p := range people {
+		conditions = append(conditions, role+"="+p)
+	}
+	return conditions
+}
+
+// writeJSON writes v as indented JSON
+func writeJSON(w io.Writer, v interface{}) error {
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+	return e.Encode(v)
+}
+
+// writeRecordTable writes one aligned row per record, with the git columns when histories were loaded
+func writeRecordTable(w io.Writer, records []*conf.Record) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, r := range records {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s", r.ID, r.Status, r.Date, r.Title)
+		if h := r.History; h != nil {
+			fmt.Fprintf(tw, "\t%s\t%s\t%s", h.Author, h.Created.Format("2006-01-02"), h.Modified.Format("2006-01-02"))
+		}
+		fmt.Fprintln(tw)
+	}
+	return tw.Flush()
+}
+
+func init() {
+	rootCmd.AddCommand(listCmd)
+
+	listCmd.Flags().StringVarP(&listFormat, "format", "f", "text", "Output format: text or json")
+	listCmd.Flags().BoolVar(&listGit, "git", false, "Enrich records with author and timeline metadata from git history")
+	listCmd.Flags().StringArrayVar(&listWhere, "where", nil, "Only list records where a field (or status) has a value, as field=value (repeatable)")
+	listCmd.Flags().StringArrayVar(&listDeciders, "decider", nil, "Only list records decided by this person (repeatable)")
+	listCmd.Flags().StringArrayVar(&listConsulted, "consulted", nil, "Only list records where this person was consulted (repeatable)")
+	listCmd.Flags().StringVarP(&listCategory, "category", "c", "", "Only list records in this category")
+	listCmd.Flags().StringArrayVar(&listInformed, "informed", nil, "Only list records where this person was informed (repeatable)")
+}
@@ -0,0 +1,105 @@
+/*
+Copyright © 2022 fleetingclarity <72276886+fleetingclarity@users.noreply.github.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	conf "github.com/fleetingclarity/adr/config"
+	"github.com/spf13/cobra"
+	"io"
+	"text/tabwriter"
+	"time"
+)
+
+var (
+	metricsFormat string
+	metricsGit    bool
+)
+
+// metricsCmd represents the metrics command
+var metricsCmd = &cobra.Command{
+	Use:     "metrics",
+	Aliases: []string{"stats"},
+	Args:    cobra.NoArgs,
+	Short:   "Report status ratios and lead times for the ADR repository",
+	Long: `Report metrics for the ADR repository: the number of records per status, the acceptance
+and rejection ratios of resolved records, the median and 90th percentile lead time from
+Proposed to Accepted, decisions per month, and supersede churn.
+
+Lead times need the status history of each record, which is read from git when the
+repository is a git work tree (disable with --git=false).
+
+Use --format json or --format openmetrics to feed dashboards. JSON lead times are in hours.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		records, err := conf.LoadRecords(config.FS, config.Repository.Path)
+		cobra.CheckErr(err)
+		if metricsGit && config.InGitRepository() {
+			for _, r := range records {
+				r.History, err = config.History(r.Path)
+				cobra.CheckErr(err)
+			}
+		}
+		m := conf.ComputeMetrics(config.ADR, records)
+		switch metricsFormat {
+		case "text":
+			cobra.CheckErr(writeMetrics(cmd.OutOrStdout(), m))
+		case "json":
+			cobra.CheckErr(writeJSON(cmd.OutOrStdout(), m))
+		case "openmetrics":
+			cobra.CheckErr(m.WriteOpenMetrics(cmd.OutOrStdout()))
+		default:
+			cobra.CheckErr(fmt.Errorf("unknown format '%s', expected text, json or openmetrics", metricsFormat))
+		}
+	},
+}
+
+// writeMetrics writes a human readable summary of m
+func writeMetrics(w io.Writer, m *conf.Metrics) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "Records\t%d\n", m.Total)
+	for _, s := range conf.SortedKeys(m.StatusCounts) {
+		fmt.Fprintf(tw, "  %s\t%d\n", s, m.StatusCounts[s])
+	}
+	fmt.Fprintf(tw, "Acceptance ratio\t%.2f\n", m.AcceptanceRatio)
+	fmt.Fprintf(tw, "Rejection ratio\t%.2f\n", m.RejectionRatio)
+	if lt := m.LeadTime; lt != nil {
+		fmt.Fprintf(tw, "Lead time (median)\t%s\n", humanDuration(lt.Median))
+		fmt.Fprintf(tw, "Lead time (p90)\t%s\n", humanDuration(lt.P90))
+	} else {
+		fmt.Fprintf(tw, "Lead time\tunavailable without git history\n")
+	}
+	fmt.Fprintf(tw, "Superseded\t%d (%.0f%% churn)\n", m.Superseded, m.SupersedeChurn*100)
+	fmt.Fprintf(tw, "Decisions per month\t\n")
+	for _, month := range conf.SortedKeys(m.PerMonth) {
+		fmt.Fprintf(tw, "  %s\t%d\n", month, m.PerMonth[month])
+	}
+	return tw.Flush()
+}
+
+// humanDuration rounds d to days when it is long enough for hours to be noise
+func humanDuration(d time.Duration) string {
+	if d >= 48*time.Hour {
+		return fmt.Sprintf("%.1f days", d.Hours()/24)
+	}
+	return d.Round(time.Minute).String()
+}
+
+func init() {
+	rootCmd.AddCommand(metricsCmd)
+
+	metricsCmd.Flags().StringVarP(&metricsFormat, "format", "f", "text", "Output format: text, json or openmetrics")
+	metricsCmd.Flags().BoolVar(&metricsGit, "git", true, "Read status history from git to compute lead times")
+}
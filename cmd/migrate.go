@@ -0,0 +1,77 @@
+/*
+Copyright © 2022 fleetingclarity <72276886+fleetingclarity@users.noreply.github.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	conf "github.com/fleetingclarity/adr/config"
+	"github.com/spf13/cobra"
+	"path"
+)
+
+var (
+	migrateTo string
+)
+
+// migrateCmd represents the migrate command
+var migrateCmd = &cobra.Command{
+	Use:   "migrate --to frontmatter|sections",
+	Args:  cobra.NoArgs,
+	Short: "Convert records between Status sections and YAML front matter",
+	Long: `Convert every record in the ADR repository to the given metadata style and make it the
+default for new records by setting adr.metadata in .adr.yaml.
+
+  sections      status and links live in the '## Status' section, other metadata
+                in 'Key: value' lines under the heading (the default)
+  frontmatter   id, title, status, date, fields and links live in YAML front matter,
+                as expected by static site generators
+
+Example usage: adr migrate --to frontmatter`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cs := conf.NewChangeset()
+		n, err := cs.Migrate(config.ADR, config.Repository.Path, migrateTo)
+		cobra.CheckErr(err)
+		cfgPath := cfgFile
+		if cfgPath == "" {
+			cfgPath = path.Join(config.WorkingDirectory, conf.DefaultConfigName+"."+conf.DefaultConfigExt)
+		}
+		config.SetMetadataStyle(migrateTo)
+		if cs.Exists(cfgPath) {
+			// edit only the style so the comments and layout of the file are kept
+			cfg, err := cs.Read(cfgPath)
+			cobra.CheckErr(err)
+			cfg, err = config.EditMetadataStyle(cfg, migrateTo)
+			cobra.CheckErr(err)
+			cobra.CheckErr(cs.Write(cfgPath, cfg))
+		} else {
+			cfg := &bytes.Buffer{}
+			cobra.CheckErr(config.Write(cfg))
+			cobra.CheckErr(cs.Write(cfgPath, cfg.Bytes()))
+		}
+		cobra.CheckErr(applyMessage(cmd, cs, fmt.Sprintf("Migrate %d ADR(s) to %s metadata", n, migrateTo)))
+		if !dryRun {
+			cmd.Printf("%d record(s) converted to %s\n", n, migrateTo)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(migrateCmd)
+
+	migrateCmd.Flags().StringVar(&migrateTo, "to", "", "The metadata style to convert to: frontmatter or sections")
+	_ = migrateCmd.MarkFlagRequired("to")
+}
@@ -0,0 +1,63 @@
+/*
+Copyright © 2022 fleetingclarity <72276886+fleetingclarity@users.noreply.github.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	conf "github.com/fleetingclarity/adr/config"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+// proposeCmd represents the propose command
+var proposeCmd = &cobra.Command{
+	Use:   "propose \"Some title\"",
+	Args:  cobra.ExactArgs(1),
+	Short: "Create a branch, a new ADR and a commit in one go",
+	Long: `Propose a decision for review. A git branch is created from the git.branch template
+(default 'adr/NNN-some-title'), the new ADR is added exactly as 'adr add' would, and the
+new file is committed using the git.message template.
+
+Example usage: adr propose "Some title"`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cs := conf.NewChangeset()
+		p, err := cs.New(config.ADR, config.Repository.Path, map[string]string{"Title": args[0]})
+		cobra.CheckErr(err)
+		values := conf.CommitValues(cs, p)
+		if dryRun {
+			cmd.Printf("Would create branch for ADR %s and commit:\n", values["Number"])
+			cobra.CheckErr(cs.Diff(cmd.OutOrStdout()))
+			return
+		}
+		cs.Operation = "adr propose " + args[0]
+		cs.Journal = config.JournalPath()
+		// write the record before switching branches, so a failure leaves the user where they were
+		cobra.CheckErr(cs.Apply())
+		branch, err := config.CreateBranch(values)
+		if err != nil {
+			undo := conf.NewChangeset()
+			if _, uErr := undo.Undo(cs.Journal, false); uErr == nil && undo.Apply() == nil {
+				_ = os.Remove(cs.Journal)
+			}
+			cobra.CheckErr(err)
+		}
+		cobra.CheckErr(config.Commit(cs.Paths(), conf.CommitValues(cs, p)))
+		cmd.Printf("Proposed %s on branch %s\n", p, branch)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(proposeCmd)
+}
@@ -0,0 +1,56 @@
+/*
+Copyright © 2022 fleetingclarity <72276886+fleetingclarity@users.noreply.github.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	conf "github.com/fleetingclarity/adr/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	leaveStub bool
+)
+
+// renameCmd represents the rename command
+var renameCmd = &cobra.Command{
+	Use:     "rename <number> \"New title\"",
+	Aliases: []string{"retitle", "mv"},
+	Args:    cobra.ExactArgs(2),
+	Short:   "Retitle an existing ADR",
+	Long: `Retitle an existing ADR. The filename is regenerated from the title template, the heading
+is replaced, the file is renamed, and links in other ADRs, in any of the configured repositories, that
+pointed at the old file are rewritten to point at the new one.
+
+Example usage: adr rename 12 "Better title"
+Use --stub to leave a redirect file at the old path for links outside the repository.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		oldPath, err := locate(args[0])
+		cobra.CheckErr(err)
+		cs := conf.NewChangeset()
+		p, err := cs.RenamePath(config.ADR, config.Repository.Path, oldPath, args[1], leaveStub, config.Dirs()...)
+		cobra.CheckErr(err)
+		cobra.CheckErr(apply(cmd, cs, p))
+		if !dryRun {
+			cmd.Printf("ADR %s renamed to %s\n", args[0], p)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(renameCmd)
+
+	renameCmd.Flags().BoolVar(&leaveStub, "stub", false, "Leave a redirect stub at the old path")
+}
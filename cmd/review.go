@@ -0,0 +1,99 @@
+/*
+Copyright © 2022 fleetingclarity <72276886+fleetingclarity@users.noreply.github.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	conf "github.com/fleetingclarity/adr/config"
+	"github.com/spf13/cobra"
+	"strings"
+)
+
+var (
+	reviewers     []string
+	reviewBy      string
+	reviewComment string
+)
+
+// reviewCmd represents the review command
+var reviewCmd = &cobra.Command{
+	Use:   "review",
+	Short: "Request and record reviews of an ADR",
+	Long: `Request reviews of a record and record approvals. Reviews are written into the record, in a
+Review section or the front matter, so they work offline and show in its history. A record
+is accepted with 'adr accept' once it has the approvals set under adr.review in .adr.yaml.`,
+	Example: `  adr review request 12 --reviewer alice --reviewer bob
+  adr review approve 12 --by alice --comment "Agreed, let's go with Postgres"
+  adr accept 12`,
+}
+
+// reviewRequestCmd represents the review request command
+var reviewRequestCmd = &cobra.Command{
+	Use:   "request <number>",
+	Args:  cobra.ExactArgs(1),
+	Short: "Request reviews of an ADR",
+	Run: func(cmd *cobra.Command, args []string) {
+		p, err := locate(args[0])
+		cobra.CheckErr(err)
+		names := conf.SplitList(strings.Join(reviewers, ","))
+		var reviews []conf.Review
+		for _, r := range names {
+			reviews = append(reviews, conf.Review{Reviewer: r, State: conf.ReviewRequested})
+		}
+		if len(reviews) == 0 {
+			cobra.CheckErr(fmt.Errorf("name at least one --reviewer"))
+		}
+		cs := conf.NewChangeset()
+		cobra.CheckErr(cs.Review(config.ADR, p, reviews...))
+		cobra.CheckErr(apply(cmd, cs, p))
+		if !dryRun {
+			cmd.Printf("Requested reviews of %s from %s\n", p, strings.Join(names, ", "))
+		}
+	},
+}
+
+// reviewApproveCmd represents the review approve command
+var reviewApproveCmd = &cobra.Command{
+	Use:   "approve <number>",
+	Args:  cobra.ExactArgs(1),
+	Short: "Record an approval of an ADR",
+	Run: func(cmd *cobra.Command, args []string) {
+		p, err := locate(args[0])
+		cobra.CheckErr(err)
+		if reviewBy == "" {
+			reviewBy = conf.DefaultAuthor(config.WorkingDirectory)
+		}
+		if reviewBy == "" {
+			cobra.CheckErr(fmt.Errorf("name the reviewer with --by, or set $%s or git's user.name", conf.AuthorEnv))
+		}
+		cs := conf.NewChangeset()
+		cobra.CheckErr(cs.Review(config.ADR, p, conf.Review{Reviewer: reviewBy, State: conf.ReviewApproved, Comment: reviewComment}))
+		cobra.CheckErr(apply(cmd, cs, p))
+		if !dryRun {
+			cmd.Printf("Recorded the approval of %s by %s\n", p, reviewBy)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(reviewCmd)
+	reviewCmd.AddCommand(reviewRequestCmd)
+	reviewCmd.AddCommand(reviewApproveCmd)
+
+	reviewRequestCmd.Flags().StringArrayVar(&reviewers, "reviewer", nil, "A reviewer to request a review from (repeatable or comma separated)")
+	reviewApproveCmd.Flags().StringVar(&reviewBy, "by", "", "The approving reviewer (default $ADR_AUTHOR or git's user.name)")
+	reviewApproveCmd.Flags().StringVarP(&reviewComment, "comment", "m", "", "A comment recorded with the approval")
+}
@@ -21,6 +21,7 @@ import (
 	conf "github.com/fleetingclarity/adr/config"
 	"os"
 	"path"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -30,6 +31,9 @@ var (
 	cfgFile string
 	config  *conf.Config
 	verbose bool
+	dryRun  bool
+	commit  bool
+	repo    string
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -62,6 +66,9 @@ func init() {
 	// will be global for your application.
 
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Verbose output useful for debugging")
+	rootCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "Print a diff of the files that would change instead of changing them")
+	rootCmd.PersistentFlags().BoolVar(&commit, "commit", false, "Commit the files changed by the command with git (default from git.commit in .adr.yaml)")
+	rootCmd.PersistentFlags().StringVar(&repo, "repo", "", "The named repository from .adr.yaml to work on (default the first)")
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
@@ -91,7 +98,96 @@ func initConfig() {
 		}
 		err = viper.Unmarshal(&config)
 		cobra.CheckErr(err)
+		cobra.CheckErr(config.Validate())
 	} else { // otherwise use default values
 		config = conf.NewDefaultConfig()
 	}
+	cobra.CheckErr(config.Use(repo))
+}
+
+// locate returns the path of the record given as a reference such as '12', 'SEC-7' or 'billing:12'
+func locate(arg string) (string, error) {
+	ref, err := conf.ParseRef(arg)
+	if err != nil {
+		return "", err
+	}
+	return config.Locate(ref)
+}
+
+// linkPair builds the LinkPair for commands given a source and a target reference such as '12', 'SEC-7' or
+// 'billing:12', along with the path of the source record
+func linkPair(source, sourceMsg, target, backMsg string) (*conf.LinkPair, string, error) {
+	sref, err := conf.ParseRef(source)
+	if err != nil {
+		return nil, "", err
+	}
+	tref, err := conf.ParseRef(target)
+	if err != nil {
+		return nil, "", err
+	}
+	sdir, err := config.Resolve(sref)
+	if err != nil {
+		return nil, "", err
+	}
+	tdir, err := config.Resolve(tref)
+	if err != nil {
+		return nil, "", err
+	}
+	sp, err := config.Locate(sref)
+	if err != nil {
+		return nil, "", err
+	}
+	tp, err := config.Locate(tref)
+	if err != nil {
+		return nil, "", err
+	}
+	return &conf.LinkPair{
+		SourceNum:     sref.Number,
+		TargetNum:     tref.Number,
+		SourceMsg:     sourceMsg,
+		BackMsg:       backMsg,
+		RepoDir:       sdir,
+		TargetRepoDir: tdir,
+		SourcePath:    sp,
+		TargetPath:    tp,
+	}, sp, nil
+}
+
+// apply writes the staged changes to disk, or prints them as a unified diff when --dry-run is set. Applied changes
+// are journaled so that 'adr undo' can reverse them, and committed when --commit (or git.commit) is set using the
+// record at primary to fill in the commit message.
+func apply(cmd *cobra.Command, cs *conf.Changeset, primary string) error {
+	return applyThen(cmd, cs, func() error {
+		return config.Commit(cs.Paths(), conf.CommitValues(cs, primary))
+	})
+}
+
+// applyMessage is apply for changes that are not about one record, committed with msg rather than the configured
+// message
+func applyMessage(cmd *cobra.Command, cs *conf.Changeset, msg string) error {
+	return applyThen(cmd, cs, func() error {
+		return config.CommitMessage(cs.Paths(), msg)
+	})
+}
+
+// applyThen applies the staged changes like apply, running commit when they should be committed
+func applyThen(cmd *cobra.Command, cs *conf.Changeset, commit func() error) error {
+	if dryRun {
+		return cs.Diff(cmd.OutOrStdout())
+	}
+	cs.Operation = strings.TrimSpace(cmd.CommandPath() + " " + strings.Join(cmd.Flags().Args(), " "))
+	cs.Journal = config.JournalPath()
+	err := cs.Apply()
+	if err != nil || !shouldCommit(cmd) {
+		return err
+	}
+	return commit()
+}
+
+// shouldCommit reports whether changes should be committed, the --commit flag wins over the configured default
+func shouldCommit(cmd *cobra.Command) bool {
+	if cmd.Flags().Changed("commit") {
+		return commit
+	}
+	return config.GitOptions().Commit
 }
@@ -0,0 +1,92 @@
+/*
+Copyright © 2022 fleetingclarity <72276886+fleetingclarity@users.noreply.github.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	conf "github.com/fleetingclarity/adr/config"
+	"github.com/spf13/cobra"
+	"io"
+	"strings"
+)
+
+var (
+	searchFormat string
+)
+
+// searchCmd represents the search command
+var searchCmd = &cobra.Command{
+	Use:     "search <query>",
+	Aliases: []string{"find", "grep"},
+	Args:    cobra.MinimumNArgs(1),
+	Short:   "Search the records in the ADR repository",
+	Long: `Search the parsed records in the ADR repository. Every free text term must appear in a
+record for it to match, matches in the title and Decision section rank higher.
+
+Fields narrow the search:
+  status:<status>       only records with this status
+  section:<name>        only look for terms in this section
+  after:<YYYY-MM-DD>    only records created on or after this date
+  before:<YYYY-MM-DD>   only records created on or before this date
+  <field>:<value>       only records with this value for a field declared in .adr.yaml
+
+Example usage: adr search 'postgres status:accepted section:decision after:2025-01-01'
+Use --format json for editor integrations.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		q, err := conf.ParseQuery(strings.Join(args, " "), config.ADR)
+		cobra.CheckErr(err)
+		records, err := conf.LoadRecords(config.FS, config.Repository.Path)
+		cobra.CheckErr(err)
+		matches := conf.Search(records, q)
+		switch searchFormat {
+		case "text":
+			cobra.CheckErr(writeMatches(cmd.OutOrStdout(), matches))
+		case "json":
+			if matches == nil {
+				matches = []*conf.Match{}
+			}
+			cobra.CheckErr(writeJSON(cmd.OutOrStdout(), matches))
+		default:
+			cobra.CheckErr(fmt.Errorf("unknown format '%s', expected text or json", searchFormat))
+		}
+	},
+}
+
+// writeMatches writes each match with its highlighted snippets
+func writeMatches(w io.Writer, matches []*conf.Match) error {
+	for _, m := range matches {
+		r := m.Record
+		if _, err := fmt.Fprintf(w, "%s  %s  %s  (%s)\n", r.ID, r.Status, r.Title, r.Path); err != nil {
+			return err
+		}
+		for _, s := range m.Snippets {
+			location := fmt.Sprintf("%d", s.Line)
+			if s.Section != "" {
+				location = s.Section + ":" + location
+			}
+			if _, err := fmt.Fprintf(w, "     %s: %s\n", location, s.Highlighted("**", "**")); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(searchCmd)
+
+	searchCmd.Flags().StringVarP(&searchFormat, "format", "f", "text", "Output format: text or json")
+}
@@ -18,7 +18,6 @@ package cmd
 import (
 	conf "github.com/fleetingclarity/adr/config"
 	"github.com/spf13/cobra"
-	"strconv"
 )
 
 // supersedeCmd represents the supersede command
@@ -30,20 +29,16 @@ var supersedeCmd = &cobra.Command{
 the Target (Superseding) ADR. The Target is updated with a backlink to the Source (Superseded).
 
 Expected usage: adr supersede <Source#> <Msg> <Target#> <BackMsg>
-Example: adr supersede 1 "some note" 2 "" # empty quotes if you don't want a message'`,
+Example: adr supersede 1 "some note" 2 "" # empty quotes if you don't want a message'
+
+Records in other repositories named in .adr.yaml are given as repository:number, e.g.
+adr supersede billing:3 "Now platform wide" platform:9 ""`,
 	Run: func(cmd *cobra.Command, args []string) {
-		sNum, err := strconv.Atoi(args[0])
-		cobra.CheckErr(err)
-		tNum, err := strconv.Atoi(args[2])
+		lp, sp, err := linkPair(args[0], args[1], args[2], args[3])
 		cobra.CheckErr(err)
-		lp := &conf.LinkPair{
-			SourceNum: sNum,
-			TargetNum: tNum,
-			SourceMsg: args[1],
-			BackMsg:   args[3],
-			RepoDir:   config.Repository.Path,
-		}
-		cobra.CheckErr(conf.Supersede(lp))
+		cs := conf.NewChangeset()
+		cobra.CheckErr(cs.Supersede(lp))
+		cobra.CheckErr(apply(cmd, cs, sp))
 	},
 }
 
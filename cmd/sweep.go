@@ -0,0 +1,65 @@
+/*
+Copyright © 2022 fleetingclarity <72276886+fleetingclarity@users.noreply.github.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	conf "github.com/fleetingclarity/adr/config"
+	"github.com/spf13/cobra"
+)
+
+// sweepCmd represents the sweep command
+var sweepCmd = &cobra.Command{
+	Use:   "sweep",
+	Args:  cobra.NoArgs,
+	Short: "Move expired and stale records to their new status",
+	Long: `Change the status of the records 'adr due' finds: records past their Expires date become
+Deprecated (or adr.revisit.expired), and records Proposed longer than adr.revisit.staleafter
+days become adr.revisit.stale when it is set, e.g. Rejected. Records due for review are
+left for people to revisit.
+
+  adr:
+    revisit:
+      reviewafter: 1y     # Review-By date of new records
+      staleafter: 30      # days a record may stay Proposed
+      expired: Deprecated
+      stale: Rejected
+
+Use --dry-run to see the changes first and --commit to commit them from a scheduled job.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		due, err := dueRecords()
+		cobra.CheckErr(err)
+		cs := conf.NewChangeset()
+		swept, err := cs.Sweep(config.ADR, due)
+		cobra.CheckErr(err)
+		if len(swept) == 0 {
+			cmd.Println("Nothing to sweep")
+			return
+		}
+		cobra.CheckErr(apply(cmd, cs, swept[0].Record.Path))
+		if dryRun {
+			return
+		}
+		for _, d := range swept {
+			cmd.Printf("%s is %s, status updated to %s\n", d.Record.Path, d.Reason, d.To)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(sweepCmd)
+
+	addDueFlags(sweepCmd)
+}
@@ -0,0 +1,54 @@
+/*
+Copyright © 2022 fleetingclarity <72276886+fleetingclarity@users.noreply.github.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	conf "github.com/fleetingclarity/adr/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	forceUndo bool
+)
+
+// undoCmd represents the undo command
+var undoCmd = &cobra.Command{
+	Use:   "undo",
+	Args:  cobra.NoArgs,
+	Short: "Reverse the last operation that changed the ADR repository",
+	Long: `Reverse the last add, update, link, supersede or rename by restoring every file it touched.
+Only the most recent operation can be undone. If any of those files were edited afterwards
+the undo is refused, use --force to discard those edits.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cs := conf.NewChangeset()
+		cs.FS = config.FS
+		op, err := cs.Undo(config.JournalPath(), forceUndo)
+		cobra.CheckErr(err)
+		if dryRun {
+			cobra.CheckErr(cs.Diff(cmd.OutOrStdout()))
+			return
+		}
+		cobra.CheckErr(cs.Apply())
+		cobra.CheckErr(cs.RemoveJournal(config.JournalPath()))
+		cmd.Printf("Undid '%s'\n", op)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(undoCmd)
+
+	undoCmd.Flags().BoolVar(&forceUndo, "force", false, "Undo even if the files were changed after the operation")
+}
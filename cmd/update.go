@@ -18,7 +18,6 @@ package cmd
 import (
 	conf "github.com/fleetingclarity/adr/config"
 	"github.com/spf13/cobra"
-	"strconv"
 )
 
 // updateCmd represents the update command
@@ -26,19 +25,23 @@ var updateCmd = &cobra.Command{
 	Use:   "update",
 	Args:  cobra.ExactArgs(2),
 	Short: "Update status of an existing ADR",
-	Long:  `Update an existing ADR Status by providing the ADR number and the new Status`,
+	Long: `Update an existing ADR Status by providing the ADR number and the new Status. Records in a
+category are numbered with its prefix or name, e.g. SEC-7 or security/7. When adr.review is set in
+.adr.yaml a record is only updated to Accepted once it has the approvals the policy asks for, see
+adr accept.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		n, err := strconv.Atoi(args[0])
 		s := args[1]
-		cobra.CheckErr(err)
 		if verbose {
 			cmd.Println("Updato potato")
 		}
-		a, err := conf.Find(config.Repository.Path, n)
-		cobra.CheckErr(err)
-		err = conf.UpdateStatus(a, s)
+		a, err := locate(args[0])
 		cobra.CheckErr(err)
-		cmd.Printf("%s status updated to %s\n", a, s)
+		cs := conf.NewChangeset()
+		cobra.CheckErr(cs.SetStatus(config.ADR, a, s))
+		cobra.CheckErr(apply(cmd, cs, a))
+		if !dryRun {
+			cmd.Printf("%s status updated to %s\n", a, s)
+		}
 	},
 }
 
@@ -0,0 +1,77 @@
+/*
+Copyright © 2022 fleetingclarity <72276886+fleetingclarity@users.noreply.github.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	conf "github.com/fleetingclarity/adr/config"
+	"github.com/spf13/cobra"
+)
+
+var verifyFormat string
+
+// verifyCmd represents the verify command
+var verifyCmd = &cobra.Command{
+	Use:   "verify [number...]",
+	Short: "Check that decisions were not rewritten after they were accepted",
+	Long: `Compare every record, or only the numbered ones, with its content at the commit where it
+first reached each status that freezes sections, e.g. where it became Accepted, and report
+the frozen sections that changed since. Links and status changes are always allowed.
+
+Which sections each status freezes is set under adr.frozen in .adr.yaml, by default every
+section of accepted, superseded and deprecated records:
+
+  adr:
+    frozen:
+      accepted: [Context, Decision]
+      superseded: ["*"]
+
+Needs the records' git history. Exits with an error when any change is found.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !config.InGitRepository() {
+			cobra.CheckErr(fmt.Errorf("verify needs the git history of the records, %s is not in a git work tree", config.Repository.Path))
+		}
+		var paths []string
+		for _, a := range args {
+			p, err := locate(a)
+			cobra.CheckErr(err)
+			paths = append(paths, p)
+		}
+		if len(args) == 0 {
+			records, err := conf.LoadRecords(config.FS, config.Repository.Path)
+			cobra.CheckErr(err)
+			for _, r := range records {
+				paths = append(paths, r.Path)
+			}
+		}
+		var findings []conf.Finding
+		for _, p := range paths {
+			f, err := config.Verify(config.ADR, p)
+			cobra.CheckErr(err)
+			findings = append(findings, f...)
+		}
+		cobra.CheckErr(writeFindings(cmd, verifyFormat, paths, findings))
+		if len(findings) > 0 {
+			cobra.CheckErr(fmt.Errorf("%d change(s) to frozen sections found", len(findings)))
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(verifyCmd)
+
+	verifyCmd.Flags().StringVarP(&verifyFormat, "format", "f", "text", "Output format: text, json, sarif, junit or github")
+}
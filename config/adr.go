@@ -1,59 +1,157 @@
 package config
 
 import (
-	"bufio"
 	"bytes"
 	"errors"
 	"fmt"
+	"github.com/fleetingclarity/adr/storage"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
-	"io"
-	"os"
+	"gopkg.in/yaml.v3"
 	"path"
-	"path/filepath"
-	"regexp"
-	"sort"
-	"strconv"
 	"strings"
 	"text/template"
-	"time"
 	"unicode"
 )
 
 type ADR struct {
-	FormatName    string
-	TitleTemplate string
-	BodyTemplate  string
+	FormatName    string   `yaml:"formatname,omitempty"`
+	TitleTemplate string   `yaml:"titletemplate,omitempty"`
+	BodyTemplate  string   `yaml:"bodytemplate,omitempty"`
+	Fields        []*Field `yaml:"fields,omitempty"`
+	// Metadata is where records keep their status, links and fields: sections (default) or frontmatter
+	Metadata string `yaml:"metadata,omitempty"`
+	// DateFormat is the Go layout of the Date of new records, ISO 8601 (2006-01-02) by default
+	DateFormat string `yaml:"dateformat,omitempty"`
+	// TimeZone is the IANA time zone new records are dated in, the local one by default
+	TimeZone string `yaml:"timezone,omitempty"`
+	// Slug are the rules for turning titles into file names
+	Slug *SlugRules `yaml:"slug,omitempty"`
+	// Categories group records with their own directory or ID prefix and sequence of numbers
+	Categories []*Category `yaml:"categories,omitempty"`
+	// Review is what records need before 'adr accept' accepts them
+	Review *ReviewPolicy `yaml:"review,omitempty"`
+	// Revisit is when records are due to be revisited, see 'adr due' and 'adr sweep'
+	Revisit *RevisitPolicy `yaml:"revisit,omitempty"`
+	// Frozen lists the sections that may no longer change once a record reaches a status, keyed by status, e.g.
+	// accepted: [Context, Decision]. '*' is every section but Status and Review. Accepted, superseded and
+	// deprecated records are entirely frozen when it is not set.
+	Frozen map[string][]string `yaml:"frozen,omitempty"`
 }
 
 // New will create a new ADR using the in-memory configuration. It will determine the
 // next number for the new ADR and write a new file to repoDir
 func (a *ADR) New(repoDir string, values map[string]string) error {
-	// 1. determine next number and pad with 0s
-	n, err := next(repoDir)
+	cs := NewChangeset()
+	_, err := cs.New(a, repoDir, values)
 	if err != nil {
 		return err
 	}
-	ns := fmt.Sprintf("%03d", n)
-	values["Title"] = Sanitize(values["Title"])
+	return cs.Apply()
+}
+
+// New stages a new ADR rendered from a's templates and returns the path it will be written to. A Date in values
+// backdates the record, otherwise it is dated now, and a Category (name or prefix) numbers it in that category.
+func (c *Changeset) New(a *ADR, repoDir string, values map[string]string) (string, error) {
+	// 1. determine next number in the category and pad with 0s
+	cat, err := a.Category(values["Category"])
+	if err != nil {
+		return "", err
+	}
+	n, err := a.next(c.FS, repoDir, cat)
+	if err != nil {
+		return "", err
+	}
+	ns := cat.id(n)
+	if cat != nil {
+		values["Category"] = cat.Name
+	}
+	for _, f := range a.Fields {
+		if values[f.Name] == "" {
+			values[f.Name] = f.Default
+		}
+		if err := f.Validate(values[f.Name], a.DateLayout()); err != nil {
+			return "", err
+		}
+	}
+	for _, role := range ParticipantRoles {
+		// set every role so templates render missing participants as empty rather than '<no value>'
+		values[role] = strings.Join(SplitList(values[role]), ", ")
+	}
+	when, err := a.RecordTime(values["Date"])
+	if err != nil {
+		return "", err
+	}
+	if values[revisitKey(RevisitReviewBy)] == "" && a.Revisit != nil {
+		values[revisitKey(RevisitReviewBy)] = a.Revisit.ReviewAfter
+	}
+	for _, label := range []string{RevisitReviewBy, RevisitExpires} {
+		if values[revisitKey(label)], err = a.revisitDate(values[revisitKey(label)], when); err != nil {
+			return "", err
+		}
+	}
+	values["Title"] = strings.TrimSpace(values["Title"])
+	values["Slug"] = a.Slugify(values["Title"])
+	if values["Title"] == "" {
+		values["Title"] = values["Slug"]
+	}
 	values["Number"] = ns
-	values["Date"] = fmt.Sprintf("%v-%v-%v", time.Now().Year(), time.Now().Month(), time.Now().Day())
+	values["Date"] = when.Format(a.DateLayout())
 	// 2. create go template
-	t := template.New(fmt.Sprintf("%s-adr", a.FormatName))
-	// 3. use title template to create new file
+	t := a.template(when)
+	// 3. use title template to determine the new file
 	tt, err := t.Parse(a.TitleTemplate)
-	f, err := titledFile(tt, repoDir, values)
-	defer f.Close()
 	if err != nil {
-		return err
+		return "", err
+	}
+	p, err := titledPath(tt, cat.dir(repoDir), fileNameValues(values))
+	if err != nil {
+		return "", err
+	}
+	if c.Exists(p) {
+		return "", &ExistsError{Path: p}
 	}
-	// 4. execute body template and write to created file
+	// 4. execute body template and stage it as the new file
 	bt, err := t.Parse(a.BodyTemplate)
-	err = bt.Execute(f, values)
 	if err != nil {
-		return err
+		return "", err
 	}
-	return nil
+	body := bytes.NewBufferString("")
+	err = bt.Execute(body, values)
+	if err != nil {
+		return "", err
+	}
+	// 5. record declared fields, participants and revisit dates the template did not already render
+	b := body.Bytes()
+	rendered := ParseRecord(p, b)
+	for _, f := range a.Fields {
+		if _, ok := rendered.Metadata[strings.ToLower(f.Name)]; !ok && values[f.Name] != "" {
+			if b, err = setMetadata(b, f.Label(), values[f.Name]); err != nil {
+				return "", err
+			}
+		}
+	}
+	for _, role := range ParticipantRoles {
+		if _, ok := rendered.Metadata[strings.ToLower(role)]; !ok && values[role] != "" {
+			if b, err = setMetadata(b, role, values[role]); err != nil {
+				return "", err
+			}
+		}
+	}
+	for _, label := range []string{RevisitReviewBy, RevisitExpires} {
+		if _, ok := rendered.Metadata[strings.ToLower(label)]; !ok && values[revisitKey(label)] != "" {
+			if b, err = setMetadata(b, label, values[revisitKey(label)]); err != nil {
+				return "", err
+			}
+		}
+	}
+	// 6. move the metadata into front matter when the repository is configured for it
+	if a.Metadata == MetadataFrontMatter {
+		if b, err = toFrontMatter(b, ns, a.Fields); err != nil {
+			return "", err
+		}
+	}
+	return p, c.Write(p, b)
 }
 
 // Sanitize ensures that the given string matches Title expectations (e.g. lowercase, no spaces, etc)
@@ -75,167 +173,165 @@ func Sanitize(title string) string {
 	}, title)
 }
 
-func titledFile(t *template.Template, repoDir string, v map[string]string) (io.WriteCloser, error) {
+// fileNameValues returns values for the title template, where .Title is the slug too so that templates written
+// before .Slug existed still produce safe file names
+func fileNameValues(values map[string]string) map[string]string {
+	v := make(map[string]string, len(values))
+	for k, val := range values {
+		v[k] = val
+	}
+	v["Title"] = values["Slug"]
+	return v
+}
+
+func titledPath(t *template.Template, repoDir string, v map[string]string) (string, error) {
 	pathBuffer := bytes.NewBufferString("")
 	err := t.Execute(pathBuffer, v)
 	if err != nil {
-		return nil, err
-	}
-	f, err := os.Create(path.Join(repoDir, pathBuffer.String()))
-	if err != nil {
-		return nil, err
+		return "", err
 	}
-	return f, err
+	return path.Join(repoDir, pathBuffer.String()), nil
 }
 
-func next(dir string) (int, error) {
-	var matches []string
-	err := filepath.WalkDir(dir, func(path string, info os.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
-		if info.IsDir() {
-			return nil
-		}
-		if matched, err := filepath.Match("[0-9]*.md", filepath.Base(path)); err != nil {
-			return err
-		} else if matched {
-			matches = append(matches, filepath.Base(path))
-		}
-		return nil
-	})
-	if err != nil {
-		return -1, err
-	}
-	if len(matches) > 0 {
-		sort.Strings(matches)
-		c := matches[len(matches)-1]
-		r := regexp.MustCompile(`^\d+`)
-		r.Longest()
-		m := r.FindString(c)
-		v, err := strconv.Atoi(m)
-		if err != nil {
-			return -1, err
-		}
-		return v + 1, nil
-	} else {
-		return 1, nil
-	}
+// NotFoundError is returned when no record has the number looked up
+type NotFoundError struct {
+	// ID is the number looked up, with the category prefix if any, e.g. 007 or SEC-007
+	ID string
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("no file with number '%s' found", e.ID)
+}
+
+// ExistsError is returned when a new record would overwrite an existing file
+type ExistsError struct {
+	Path string
+}
+
+func (e *ExistsError) Error() string {
+	return fmt.Sprintf("unable to create %s, it already exists", e.Path)
 }
 
-// Find will return the repoDir/NNN-file-name.md for the specified ADR number if it exists in the repoDir
+// Find will return the repoDir/NNN-file-name.md for the specified ADR number if it exists in the repoDir. Find knows
+// no categories, so for repositories with category directories use ADR.FindRecord, which leaves them out.
 func Find(repoDir string, num int) (string, error) {
+	return find(storage.OS, nil, repoDir, num)
+}
+
+// find looks for the uncategorized ADR number in the repoDir of fsys with settings a, which may be nil, see Find
+func find(fsys storage.FS, a *ADR, repoDir string, num int) (string, error) {
 	if num > 999 {
 		return "", errors.New("the adr tool does not support 4 digit records, please create a Github issue if you require over a thousand records")
 	}
-	var matches []string
-	err := filepath.WalkDir(repoDir, func(path string, info os.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
-		if info.IsDir() {
-			return nil
-		}
-		if matched, err := filepath.Match(fmt.Sprintf("%03d*.md", num), filepath.Base(path)); err != nil {
-			return err
-		} else if matched {
-			matches = append(matches, path)
-		}
-		return nil
-	})
-	if err != nil {
-		return "", err
-	}
-	// always take the first match... hopefully there's only one
-	if len(matches) < 1 {
-		return "", errors.New(fmt.Sprintf("no file with number '%03d' found", num))
+	if a == nil {
+		a = &ADR{}
 	}
-	return matches[0], nil
+	return a.FindRecord(fsys, repoDir, nil, num)
 }
 
 // UpdateStatus will search for the Status section and replace the existing status with the 'to' status
 func UpdateStatus(path, to string) error {
-	r, err := os.Open(path) // closed at end of function
-	if err != nil {
-		return err
-	}
-	w, err := os.Create(path + ".tmp")
-	if err != nil {
-		return err
-	}
-	defer w.Close()
-	scanner := bufio.NewScanner(r)
-	err = replaceSectionContent(scanner, w, "Status", to)
+	cs := NewChangeset()
+	err := cs.UpdateStatus(path, to)
 	if err != nil {
 		return err
 	}
-	err = r.Close()
-	if err != nil {
-		return err
+	return cs.Apply()
+}
+
+// UpdateStatus stages the replacement of the status of the ADR at path with the 'to' status
+func (c *Changeset) UpdateStatus(path, to string) error {
+	return c.rewrite(path, func(in []byte) ([]byte, error) {
+		if hasFrontMatter(in) {
+			return editFrontMatter(in, func(m *yaml.Node) error {
+				return setFrontMatterValue(m, "status", cases.Title(language.AmericanEnglish).String(to))
+			})
+		}
+		return replaceSectionContent(in, "Status", to)
+	})
+}
+
+// statusOf returns the status from the front matter of an ADR, or the first line of its Status section
+func statusOf(in []byte) string {
+	lines, _ := splitLines(in)
+	if fm, _, ok := splitFrontMatter(lines); ok {
+		m, err := parseFrontMatter(fm)
+		if err != nil {
+			return ""
+		}
+		if n := frontMatterValue(m, "status"); n != nil {
+			return n.Value
+		}
+		return ""
 	}
-	err = os.Rename(path+".tmp", path)
-	if err != nil {
-		return err
+	inSection := false
+	for _, line := range lines {
+		if inSection && strings.HasPrefix(line, "## ") {
+			break
+		}
+		if inSection && strings.TrimSpace(line) != "" {
+			return strings.TrimSpace(line)
+		}
+		if line == "## Status" {
+			inSection = true
+		}
 	}
-	return nil
+	return ""
 }
 
 // replaceSectionContent will find the identified section if it exists and replace all content until the following section
-func replaceSectionContent(scanner *bufio.Scanner, wc io.WriteCloser, sectionPattern string, replacement string) error {
+func replaceSectionContent(in []byte, sectionPattern string, replacement string) ([]byte, error) {
 	newSection := "## "
 	inSection := false
 	replacementWritten := false
-	defer wc.Close()
-	for scanner.Scan() {
-		line := scanner.Text()
-		if inSection && replacementWritten && strings.Contains(line, newSection) {
-			inSection = false
+	return editLines(in, func(lines []string) []string {
+		for i, line := range lines {
+			if inSection && replacementWritten && strings.Contains(line, newSection) {
+				inSection = false
+			}
+			if inSection && replacementWritten {
+				line = ""
+			}
+			if inSection && !replacementWritten {
+				caser := cases.Title(language.AmericanEnglish)
+				line = caser.String(replacement)
+				replacementWritten = true
+			}
+			if line == newSection+sectionPattern {
+				// our next line should be what we're looking to replace
+				inSection = true
+			}
+			lines[i] = line
 		}
-		if inSection && replacementWritten {
-			line = ""
-		}
-		if inSection && !replacementWritten {
-			caser := cases.Title(language.AmericanEnglish)
-			line = caser.String(replacement)
-			replacementWritten = true
-		}
-		if line == newSection+sectionPattern {
-			// our next line should be what we're looking to replace
-			inSection = true
-		}
-		b := []byte(line + "\n")
-		_, err := wc.Write(b)
-		if err != nil {
-			return err
-		}
-	}
-	return nil
+		return lines
+	}), nil
 }
 
 // appendToSection will insert content just before the section following the identified section
-func appendToSection(scanner *bufio.Scanner, wc io.WriteCloser, sectionPattern string, newContent string) error {
+func appendToSection(in []byte, sectionPattern string, newContent string) ([]byte, error) {
 	newSection := "## "
 	inSection := false
 	appended := false
-	defer wc.Close()
-	for scanner.Scan() {
-		line := scanner.Text()
-		if inSection && !appended && strings.Contains(line, newSection) {
-			line = newContent + "\n" + line
-			inSection = false
-			appended = true
+	return editLines(in, func(lines []string) []string {
+		var out []string
+		for _, line := range lines {
+			if inSection && !appended && strings.Contains(line, newSection) {
+				out = append(out, strings.Split(newContent, "\n")...)
+				inSection = false
+				appended = true
+			}
+			if line == newSection+sectionPattern {
+				// we're in the section, now just need to identify the start of the next section to enable insert
+				inSection = true
+			}
+			out = append(out, line)
 		}
-		if line == newSection+sectionPattern {
-			// we're in the section, now just need to identify the start of the next section to enable insert
-			inSection = true
+		if inSection && !appended {
+			// the section is the last one, e.g. in the short records adr-tools writes
+			out = append(out, strings.Split(newContent, "\n")...)
 		}
-		b := []byte(line + "\n")
-		_, err := wc.Write(b)
-		if err != nil {
-			return err
-		}
-	}
-	return nil
+		return out
+	}), nil
 }
 
 type LinkPair struct {
@@ -244,25 +340,59 @@ type LinkPair struct {
 	SourceMsg string
 	BackMsg   string
 	RepoDir   string
+	// TargetRepoDir is the repository of the target when it is not in RepoDir
+	TargetRepoDir string
+	// SourcePath and TargetPath are used instead of finding the numbers when given, e.g. for categorized records
+	SourcePath string
+	TargetPath string
+}
+
+// paths finds the source and target records of the pair in fsys
+func (p *LinkPair) paths(fsys storage.FS) (string, string, error) {
+	if p.SourcePath != "" && p.TargetPath != "" {
+		return p.SourcePath, p.TargetPath, nil
+	}
+	sp, err := find(fsys, nil, p.RepoDir, p.SourceNum)
+	if err != nil {
+		return "", "", err
+	}
+	targetDir := p.TargetRepoDir
+	if targetDir == "" {
+		targetDir = p.RepoDir
+	}
+	tp, err := find(fsys, nil, targetDir, p.TargetNum)
+	if err != nil {
+		return "", "", err
+	}
+	return sp, tp, nil
 }
 
 // Link will use the LinkPair to insert links into the 'Status' section
 func Link(p *LinkPair) error {
-	sp, err := Find(p.RepoDir, p.SourceNum)
+	cs := NewChangeset()
+	err := cs.Link(p)
 	if err != nil {
 		return err
 	}
-	tp, err := Find(p.RepoDir, p.TargetNum)
+	return cs.Apply()
+}
+
+// Link stages the links described by the LinkPair
+func (c *Changeset) Link(p *LinkPair) error {
+	sp, tp, err := p.paths(c.FS)
 	if err != nil {
 		return err
 	}
 	sbase := path.Base(sp)
 	tbase := path.Base(tp)
-	err = appendForLink(sp, fmt.Sprintf("[Links to %s: %s](./%s)", sbase, p.SourceMsg, tbase))
+	toTarget, toSource := relativeTarget(sp, tp), relativeTarget(tp, sp)
+	err = c.addLink(sp, Relation{Rel: "Links to", Target: toTarget, Message: p.SourceMsg},
+		fmt.Sprintf("[Links to %s: %s](%s)", sbase, p.SourceMsg, href(toTarget)))
 	if err != nil {
 		return err
 	}
-	err = appendForLink(tp, fmt.Sprintf("[Links to %s: %s](./%s)", tbase, p.BackMsg, sbase))
+	err = c.addLink(tp, Relation{Rel: "Links to", Target: toSource, Message: p.BackMsg},
+		fmt.Sprintf("[Links to %s: %s](%s)", tbase, p.BackMsg, href(toSource)))
 	if err != nil {
 		return err
 	}
@@ -272,24 +402,21 @@ func Link(p *LinkPair) error {
 // Supersede will change the Source status to 'Superseded' and link to the Target. It will also append the
 // 'Supersedes' backlink to the Target status
 func Supersede(p *LinkPair) error {
-	sp, err := Find(p.RepoDir, p.SourceNum)
-	if err != nil {
-		return err
-	}
-	tp, err := Find(p.RepoDir, p.TargetNum)
-	if err != nil {
-		return err
-	}
-	tFile, err := os.Open(tp)
+	cs := NewChangeset()
+	err := cs.Supersede(p)
 	if err != nil {
 		return err
 	}
-	tWC, err := os.Create(tp + ".tmp")
+	return cs.Apply()
+}
+
+// Supersede stages the status change and links described by the LinkPair
+func (c *Changeset) Supersede(p *LinkPair) error {
+	sp, tp, err := p.paths(c.FS)
 	if err != nil {
 		return err
 	}
-	sbase := path.Base(sp)
-	tbase := path.Base(tp)
+	toTarget, toSource := relativeTarget(sp, tp), relativeTarget(tp, sp)
 	var smsg, tmsg string
 	if len(p.SourceMsg) > 0 {
 		smsg = ": " + p.SourceMsg
@@ -297,65 +424,39 @@ func Supersede(p *LinkPair) error {
 	if len(p.BackMsg) > 0 {
 		tmsg = ": " + p.BackMsg
 	}
-	err = UpdateStatus(sp, "Superseded")
-	if err != nil {
-		return err
-	}
-	sFile, err := os.Open(sp)
-	if err != nil {
-		return err
-	}
-	sWC, err := os.Create(sp + ".tmp")
-	if err != nil {
-		return err
-	}
-	sScanner := bufio.NewScanner(sFile)
-	err = appendToSection(sScanner, sWC, "Status", fmt.Sprintf("[Superseded by %s%s](./%s)", tbase, smsg, tbase))
-	if err != nil {
-		_ = sFile.Close()
-		return err
-	}
-	_ = sFile.Close()
-	err = os.Rename(sp+".tmp", sp)
+	err = c.UpdateStatus(sp, "Superseded")
 	if err != nil {
 		return err
 	}
-	tScanner := bufio.NewScanner(tFile)
-	err = appendToSection(tScanner, tWC, "Status", fmt.Sprintf("[Supersedes %s%s](./%s)", sbase, tmsg, sbase))
+	err = c.addLink(sp, Relation{Rel: "Superseded by", Target: toTarget, Message: p.SourceMsg},
+		fmt.Sprintf("[Superseded by %s%s](%s)", toTarget, smsg, href(toTarget)))
 	if err != nil {
-		_ = tFile.Close()
 		return err
 	}
-	_ = tFile.Close()
-	err = os.Rename(tp+".tmp", tp)
+	err = c.addLink(tp, Relation{Rel: "Supersedes", Target: toSource, Message: p.BackMsg},
+		fmt.Sprintf("[Supersedes %s%s](%s)", toSource, tmsg, href(toSource)))
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func appendForLink(path, newContent string) error {
-	f, err := os.Open(path)
-	if err != nil {
-		return err
-	}
-	scanner := bufio.NewScanner(f)
-	wc, err := os.Create(path + ".tmp")
-	err = appendToSection(scanner, wc, "Status", newContent)
-	if err != nil {
-		return err
-	}
-	f.Close()
-	err = os.Rename(path+".tmp", path)
-	if err != nil {
-		return err
-	}
-	return nil
+// addLink stages a link from the ADR at path, as an entry in the front matter links or as the given markdown in
+// the Status section
+func (c *Changeset) addLink(path string, l Relation, markdown string) error {
+	return c.rewrite(path, func(in []byte) ([]byte, error) {
+		if hasFrontMatter(in) {
+			return editFrontMatter(in, func(m *yaml.Node) error {
+				return setFrontMatterValue(m, "links", append(frontMatterRelations(m), l))
+			})
+		}
+		return appendToSection(in, "Status", markdown)
+	})
 }
 
 const (
-	defaultTitleTemplate = "{{ .Number }}-{{ .Title }}.md"
-	defaultBodyTemplate  = `# {{ .Number }}-{{ .Title }}
+	defaultTitleTemplate = "{{ .Number }}-{{ .Slug }}.md"
+	defaultBodyTemplate  = `# {{ .Number }}. {{ .Title }}
 Date: {{ .Date }}
 
 ## Status
@@ -194,3 +194,106 @@ func handleHarnessErr(t *testing.T, e error) {
 		t.Fatal(e)
 	}
 }
+
+func Test_Rename(t *testing.T) {
+	startDir, workDir, err := setup()
+	handleHarnessErr(t, err)
+	defer cleanup(startDir, workDir)
+	c := NewDefaultConfig()
+	repoDir := path.Join(workDir, DefaultRepositoryDir)
+	handleHarnessErr(t, c.New(repoDir, map[string]string{"Title": "first"}))
+	handleHarnessErr(t, c.New(repoDir, map[string]string{"Title": "second"}))
+	handleHarnessErr(t, Link(&LinkPair{SourceNum: 2, TargetNum: 1, SourceMsg: "amends", BackMsg: "amended by", RepoDir: repoDir}))
+	// begin test
+	p, err := c.Rename(repoDir, 1, "Better Title", true)
+	assert.NoError(t, err)
+	assert.Equal(t, path.Join(repoDir, "001-better-title.md"), p)
+	renamed, err := os.ReadFile(p)
+	assert.NoError(t, err)
+	assert.Contains(t, string(renamed), "# 001. Better Title\n", "heading should be re-rendered from the body template with the title as typed")
+	linker, err := os.ReadFile(path.Join(repoDir, "002-second.md"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(linker), "(./001-better-title.md)", "inbound links should point at the new filename")
+	assert.NotContains(t, string(linker), "001-first.md")
+	stub, err := os.ReadFile(path.Join(repoDir, "001-first.md"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(stub), "(./001-better-title.md)", "stub should redirect to the new filename")
+	found, err := Find(repoDir, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, p, found, "Find should skip the redirect stub")
+}
+
+func Test_RenameKeepsTheFileMode(t *testing.T) {
+	startDir, workDir, err := setup()
+	handleHarnessErr(t, err)
+	defer cleanup(startDir, workDir)
+	c := NewDefaultConfig()
+	repoDir := path.Join(workDir, DefaultRepositoryDir)
+	handleHarnessErr(t, c.New(repoDir, map[string]string{"Title": "first"}))
+	handleHarnessErr(t, os.Chmod(path.Join(repoDir, "001-first.md"), 0640))
+	// begin test
+	p, err := c.Rename(repoDir, 1, "Better Title", false)
+	assert.NoError(t, err)
+	fi, err := os.Stat(p)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0640), fi.Mode().Perm())
+}
+
+func Test_RenameRewritesOnlyLinksToTheRecord(t *testing.T) {
+	startDir, workDir, err := setup()
+	handleHarnessErr(t, err)
+	defer cleanup(startDir, workDir)
+	c := NewDefaultConfig()
+	c.Categories = []*Category{{Name: "security", Prefix: "SEC"}}
+	repoDir, otherDir := path.Join(workDir, DefaultRepositoryDir), path.Join(workDir, "billing")
+	handleHarnessErr(t, os.MkdirAll(otherDir, 0755))
+	handleHarnessErr(t, c.New(repoDir, map[string]string{"Title": "use pg"}))
+	handleHarnessErr(t, c.New(repoDir, map[string]string{"Title": "use pg", "Category": "SEC"}))
+	handleHarnessErr(t, c.New(repoDir, map[string]string{"Title": "pool connections"}))
+	other := NewDefaultConfig()
+	other.Metadata = MetadataFrontMatter
+	handleHarnessErr(t, other.New(otherDir, map[string]string{"Title": "bill in pg"}))
+	pg, secPG := path.Join(repoDir, "001-use-pg.md"), path.Join(repoDir, "SEC-001-use-pg.md")
+	pool, bill := path.Join(repoDir, "002-pool-connections.md"), path.Join(otherDir, "001-bill-in-pg.md")
+	handleHarnessErr(t, Link(&LinkPair{SourcePath: pool, TargetPath: secPG, SourceMsg: "secures", BackMsg: "secured by"}))
+	handleHarnessErr(t, Link(&LinkPair{SourcePath: pool, TargetPath: pg, SourceMsg: "pools", BackMsg: "pooled by"}))
+	handleHarnessErr(t, Supersede(&LinkPair{SourcePath: pg, TargetPath: bill, SourceMsg: "moved"}))
+	// begin test
+	cs := NewChangeset()
+	p, err := cs.RenamePath(c.ADR, repoDir, pg, "use mysql", false, otherDir)
+	assert.NoError(t, err)
+	assert.NoError(t, cs.Apply())
+	b, err := os.ReadFile(pool)
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), "(./SEC-001-use-pg.md)", "a link to a record whose name ends the same is kept")
+	assert.Contains(t, string(b), "(./001-use-mysql.md)")
+	assert.NotContains(t, string(b), "(./001-use-pg.md)")
+	b, err = os.ReadFile(bill)
+	assert.NoError(t, err)
+	r := ParseRecord(bill, b)
+	assert.Equal(t, []Relation{{Rel: "Supersedes", Target: "../docs/decisions/001-use-mysql.md"}}, r.Links,
+		"links from the other repositories are rewritten too")
+	b, err = os.ReadFile(p)
+	assert.NoError(t, err)
+	r = ParseRecord(p, b)
+	assert.Equal(t, "Superseded by", r.Links[0].Rel)
+	assert.Equal(t, "../../billing/001-bill-in-pg.md", r.Links[0].Target)
+}
+
+func Test_RenameFrontMatterRecord(t *testing.T) {
+	startDir, workDir, err := setup()
+	handleHarnessErr(t, err)
+	defer cleanup(startDir, workDir)
+	c := NewDefaultConfig()
+	c.Metadata = MetadataFrontMatter
+	repoDir := path.Join(workDir, DefaultRepositoryDir)
+	handleHarnessErr(t, c.New(repoDir, map[string]string{"Title": "use pg"}))
+	// begin test
+	p, err := c.Rename(repoDir, 1, "use mysql", false)
+	assert.NoError(t, err)
+	b, err := os.ReadFile(p)
+	assert.NoError(t, err)
+	r := ParseRecord(p, b)
+	assert.Equal(t, "use mysql", r.Title, "the title in the front matter is replaced too")
+	assert.Contains(t, string(b), "# 001. use mysql\n")
+}
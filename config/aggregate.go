@@ -0,0 +1,285 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"github.com/fleetingclarity/adr/storage"
+	"gopkg.in/yaml.v3"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// adrToolsDirFile is the file adr-tools keeps the path of its records in, relative to the project
+const adrToolsDirFile = ".adr-dir"
+
+// adrToolsDefaultDir is where adr-tools keeps records when .adr-dir is empty
+const adrToolsDefaultDir = "doc/adr"
+
+// LoadConfig reads the .adr.yaml in dir of fsys (the disk when nil), filling in the defaults for anything it leaves
+// out. Records are looked up in fsys too.
+func LoadConfig(fsys storage.FS, dir string) (*Config, error) {
+	b, err := fileSystem(fsys).ReadFile(filepath.Join(dir, DefaultConfigName+"."+DefaultConfigExt))
+	if err != nil {
+		return nil, err
+	}
+	c := &Config{}
+	if err = yaml.Unmarshal(b, c); err != nil {
+		return nil, fmt.Errorf("unable to read the configuration in %s: %w", dir, err)
+	}
+	defaults := NewDefaultConfig()
+	c.WorkingDirectory, c.CfgFileName, c.CfgFileExt, c.FS = dir, defaults.CfgFileName, defaults.CfgFileExt, fsys
+	if c.ADR == nil {
+		c.ADR = defaults.ADR
+	} else {
+		c.ADR = defaults.ADR.merge(c.ADR)
+	}
+	if c.Repository == nil && len(c.Repositories) == 0 {
+		c.Repository = defaults.Repository
+	}
+	if err = c.Validate(); err != nil {
+		return nil, fmt.Errorf("unable to read the configuration in %s: %w", dir, err)
+	}
+	return c, nil
+}
+
+// Source is one ADR repository found by Discover
+type Source struct {
+	// Name qualifies the IDs of the source's records, e.g. billing in billing:007
+	Name string `json:"name"`
+	// Dir is the directory of the project the source was found in and Path where its records are
+	Dir  string `json:"dir"`
+	Path string `json:"path"`
+	// Format is adr for sources with a .adr.yaml and adr-tools for those with a .adr-dir
+	Format string `json:"format"`
+	// ADR is the settings of an adr source, as configured in its project, nil for adr-tools sources
+	ADR *ADR `json:"-"`
+}
+
+// Discover walks the given directories of fsys (the disk when nil) for projects managed by this tool (with a
+// .adr.yaml) or by adr-tools (with a .adr-dir) and returns their ADR repositories, named after their project
+// directories and, for projects with several repositories, the repository names
+func Discover(fsys storage.FS, roots ...string) ([]*Source, error) {
+	fsys = fileSystem(fsys)
+	var sources []*Source
+	for _, root := range roots {
+		err := storage.WalkDir(fsys, root, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() {
+				return nil
+			}
+			if p != root && (strings.HasPrefix(d.Name(), ".") || d.Name() == "node_modules" || d.Name() == "vendor") {
+				return filepath.SkipDir
+			}
+			found, err := discoverIn(fsys, p)
+			sources = append(sources, found...)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	nameSources(sources)
+	return sources, nil
+}
+
+// discoverIn returns the sources of the project in dir of fsys, if it is one
+func discoverIn(fsys storage.FS, dir string) ([]*Source, error) {
+	if _, err := fsys.Stat(filepath.Join(dir, DefaultConfigName+"."+DefaultConfigExt)); err == nil {
+		c, err := LoadConfig(fsys, dir)
+		if err != nil {
+			return nil, err
+		}
+		var sources []*Source
+		if c.Repository != nil {
+			sources = append(sources, &Source{Name: c.Repository.Name, Dir: dir, Path: filepath.Join(dir, c.Repository.Path),
+				Format: "adr", ADR: c.ADR.merge(c.Repository.ADR)})
+		}
+		for _, r := range c.Repositories {
+			sources = append(sources, &Source{Name: r.Name, Dir: dir, Path: filepath.Join(dir, r.Path), Format: "adr",
+				ADR: c.ADR.merge(r.ADR)})
+		}
+		return sources, nil
+	}
+	b, err := fsys.ReadFile(filepath.Join(dir, adrToolsDirFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	records := strings.TrimSpace(string(b))
+	if records == "" {
+		records = adrToolsDefaultDir
+	}
+	return []*Source{{Dir: dir, Path: filepath.Join(dir, records), Format: "adr-tools"}}, nil
+}
+
+// nameSources names each source after its project directory, qualified by its repository name when the project
+// has several, and adds the parent directory to names that would otherwise be ambiguous
+func nameSources(sources []*Source) {
+	perDir := make(map[string]int)
+	for _, s := range sources {
+		perDir[s.Dir]++
+	}
+	name := func(s *Source, parents int) string {
+		dir, _ := filepath.Abs(s.Dir)
+		parts := []string{filepath.Base(dir)}
+		for i := 0; i < parents; i++ {
+			dir = filepath.Dir(dir)
+			parts = append([]string{filepath.Base(dir)}, parts...)
+		}
+		n := strings.Join(parts, "/")
+		if perDir[s.Dir] > 1 && s.Name != "" {
+			n += "/" + s.Name
+		}
+		return n
+	}
+	parents := make([]int, len(sources))
+	for round := 0; round < 8; round++ {
+		seen := make(map[string][]int)
+		for i, s := range sources {
+			n := name(s, parents[i])
+			seen[n] = append(seen[n], i)
+		}
+		ambiguous := false
+		for _, same := range seen {
+			if len(same) > 1 {
+				ambiguous = true
+				for _, i := range same {
+					parents[i]++
+				}
+			}
+		}
+		if !ambiguous {
+			break
+		}
+	}
+	for i, s := range sources {
+		s.Name = name(s, parents[i])
+	}
+}
+
+// Entry is a record in a Catalogue
+type Entry struct {
+	// ID is qualified by the source's name, e.g. billing:007, and by the category name for categories without a
+	// prefix, e.g. billing:data/007
+	ID     string  `json:"id"`
+	Source string  `json:"source"`
+	Record *Record `json:"record"`
+	// Links are the record's links with the qualified IDs of the records they point at
+	Links []ResolvedLink `json:"links,omitempty"`
+}
+
+// ResolvedLink is a link between records, ID is empty when the target is not in the catalogue
+type ResolvedLink struct {
+	Relation
+	ID string `json:"id,omitempty"`
+}
+
+// Catalogue is the combined view of the records of many sources
+type Catalogue struct {
+	Sources []*Source `json:"sources"`
+	Entries []*Entry  `json:"entries"`
+}
+
+// Aggregate loads the records of every source from fsys (the disk when nil) and resolves the links between them,
+// including links across sources
+func Aggregate(fsys storage.FS, sources []*Source) (*Catalogue, error) {
+	fsys = fileSystem(fsys)
+	cat := &Catalogue{Sources: sources}
+	byPath := make(map[string]*Entry)
+	for _, s := range sources {
+		if _, err := fsys.Stat(s.Path); errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		records, err := LoadRecords(fsys, s.Path)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load the records of %s: %w", s.Name, err)
+		}
+		for _, r := range records {
+			e := &Entry{ID: s.Name + ":" + s.id(r), Source: s.Name, Record: r}
+			cat.Entries = append(cat.Entries, e)
+			if abs, err := filepath.Abs(r.Path); err == nil {
+				byPath[abs] = e
+			}
+		}
+	}
+	for _, e := range cat.Entries {
+		for _, l := range e.Record.Links {
+			resolved := ResolvedLink{Relation: l}
+			if abs, err := filepath.Abs(filepath.Join(filepath.Dir(e.Record.Path), filepath.FromSlash(l.Target))); err == nil {
+				if target, ok := byPath[abs]; ok {
+					resolved.ID = target.ID
+				}
+			}
+			e.Links = append(e.Links, resolved)
+		}
+	}
+	sort.SliceStable(cat.Entries, func(i, j int) bool {
+		return cat.Entries[i].Source < cat.Entries[j].Source
+	})
+	return cat, nil
+}
+
+// id returns the ID of record r within the source, qualified by the name of its category when the category keeps its
+// records in a directory without a prefix, e.g. data/001, as the IDs alone are not unique then
+func (s *Source) id(r *Record) string {
+	if s.ADR == nil {
+		return r.ID
+	}
+	if c := s.ADR.CategoryOf(s.Path, r.Path); c != nil && c.Prefix == "" {
+		return c.Name + "/" + r.ID
+	}
+	return r.ID
+}
+
+// WriteMarkdown writes the catalogue as a markdown index with a section per source, linking to every record
+// relative to dir, where the index will be kept
+func (cat *Catalogue) WriteMarkdown(w io.Writer, dir string) error {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	b := &strings.Builder{}
+	fmt.Fprintln(b, "# Architecture decision records")
+	source := ""
+	for _, e := range cat.Entries {
+		if e.Source != source {
+			source = e.Source
+			fmt.Fprintf(b, "\n## %s\n\n| ID | Title | Status | Date | Links |\n| --- | --- | --- | --- | --- |\n", source)
+		}
+		href := filepath.ToSlash(e.Record.Path)
+		abs, err := filepath.Abs(e.Record.Path)
+		if err == nil {
+			if rel, err := filepath.Rel(absDir, abs); err == nil {
+				href = filepath.ToSlash(rel)
+			}
+		}
+		var links []string
+		for _, l := range e.Links {
+			if l.ID != "" {
+				links = append(links, fmt.Sprintf("%s [%s](#%s)", l.Rel, l.ID, anchor(l.ID)))
+			} else {
+				links = append(links, fmt.Sprintf("%s %s", l.Rel, l.Target))
+			}
+		}
+		fmt.Fprintf(b, "| <a id=\"%s\"></a>%s | [%s](%s) | %s | %s | %s |\n", anchor(e.ID), e.ID, markdownCell(e.Record.Title), href,
+			markdownCell(e.Record.Status), markdownCell(e.Record.Date), strings.Join(links, "<br>"))
+	}
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+// anchor turns a qualified ID into an HTML anchor, e.g. billing:007 into billing-007
+func anchor(id string) string {
+	return strings.NewReplacer(":", "-", "/", "-").Replace(strings.ToLower(id))
+}
+
+// markdownCell escapes text for a markdown table cell
+func markdownCell(s string) string {
+	return strings.ReplaceAll(s, "|", "\\|")
+}
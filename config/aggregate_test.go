@@ -0,0 +1,120 @@
+package config
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path"
+	"testing"
+)
+
+func Test_Aggregate(t *testing.T) {
+	startDir, workDir, err := setup()
+	handleHarnessErr(t, err)
+	defer cleanup(startDir, workDir)
+	// a project managed with adr
+	billing := path.Join(workDir, "billing")
+	c := NewDefaultConfig()
+	handleHarnessErr(t, os.MkdirAll(billing, 0755))
+	f, err := os.Create(path.Join(billing, ".adr.yaml"))
+	handleHarnessErr(t, err)
+	handleHarnessErr(t, c.Write(f))
+	handleHarnessErr(t, f.Close())
+	billingRepo := path.Join(billing, c.Repository.Path)
+	handleHarnessErr(t, os.MkdirAll(billingRepo, 0755))
+	handleHarnessErr(t, c.New(billingRepo, map[string]string{"Title": "Use Postgres"}))
+	// a project managed with adr-tools
+	platform := path.Join(workDir, "platform")
+	handleHarnessErr(t, os.MkdirAll(path.Join(platform, "doc/arch"), 0755))
+	handleHarnessErr(t, os.WriteFile(path.Join(platform, ".adr-dir"), []byte("doc/arch\n"), 0644))
+	handleHarnessErr(t, os.WriteFile(path.Join(platform, "doc/arch/0001-record-decisions.md"),
+		[]byte("# 1. Record decisions\n\nDate: 2020-01-01\n\n## Status\n\nAccepted\n\nAmended by [2. Use Kafka](0002-use-kafka.md)\n"), 0644))
+	handleHarnessErr(t, os.WriteFile(path.Join(platform, "doc/arch/0002-use-kafka.md"),
+		[]byte("# 2. Use Kafka\n\nDate: 2020-02-01\n\n## Status\n\nAccepted\n\nAmends [1. Record decisions](0001-record-decisions.md)\n"), 0644))
+	// checkouts are linked across
+	handleHarnessErr(t, Link(&LinkPair{SourcePath: path.Join(billingRepo, "001-use-postgres.md"), SourceMsg: "builds on",
+		TargetPath: path.Join(platform, "doc/arch/0002-use-kafka.md"), BackMsg: "used by"}))
+	// hidden directories below the roots are not searched
+	handleHarnessErr(t, os.MkdirAll(path.Join(platform, ".cache/old"), 0755))
+	handleHarnessErr(t, os.WriteFile(path.Join(platform, ".cache/old/.adr-dir"), nil, 0644))
+	// begin test
+	sources, err := Discover(nil, billing, platform)
+	assert.NoError(t, err)
+	assert.Len(t, sources, 2)
+	cat, err := Aggregate(nil, sources)
+	assert.NoError(t, err)
+	ids := make(map[string][]string)
+	for _, e := range cat.Entries {
+		for _, l := range e.Links {
+			ids[e.ID] = append(ids[e.ID], l.ID)
+		}
+	}
+	assert.Equal(t, map[string][]string{
+		"billing:001":   {"platform:0002"},
+		"platform:0001": {"platform:0002"},
+		"platform:0002": {"platform:0001", "billing:001"},
+	}, ids)
+	b := &bytes.Buffer{}
+	assert.NoError(t, cat.WriteMarkdown(b, workDir))
+	assert.Contains(t, b.String(), "## platform\n")
+	assert.Contains(t, b.String(), "[Use Postgres](billing/docs/decisions/001-use-postgres.md)")
+	assert.Contains(t, b.String(), "[platform:0002](#platform-0002)")
+	assert.Equal(t, "Amended by", cat.Entries[1].Links[0].Rel, "adr-tools links are read")
+}
+
+func Test_AggregateQualifiesCategoryDirectories(t *testing.T) {
+	startDir, workDir, err := setup()
+	handleHarnessErr(t, err)
+	defer cleanup(startDir, workDir)
+	billing := path.Join(workDir, "billing")
+	c := NewDefaultConfig()
+	c.Categories = []*Category{{Name: "data", Directory: "data"}, {Name: "security", Prefix: "SEC"}}
+	handleHarnessErr(t, os.MkdirAll(billing, 0755))
+	f, err := os.Create(path.Join(billing, ".adr.yaml"))
+	handleHarnessErr(t, err)
+	handleHarnessErr(t, c.Write(f))
+	handleHarnessErr(t, f.Close())
+	repoDir := path.Join(billing, c.Repository.Path)
+	handleHarnessErr(t, os.MkdirAll(repoDir, 0755))
+	handleHarnessErr(t, c.New(repoDir, map[string]string{"Title": "plain"}))
+	handleHarnessErr(t, c.New(repoDir, map[string]string{"Title": "pg", "Category": "data"}))
+	handleHarnessErr(t, c.New(repoDir, map[string]string{"Title": "mtls", "Category": "security"}))
+	handleHarnessErr(t, Link(&LinkPair{SourcePath: path.Join(repoDir, "001-plain.md"), SourceMsg: "stores in",
+		TargetPath: path.Join(repoDir, "data/001-pg.md"), BackMsg: "stores"}))
+	// begin test
+	sources, err := Discover(nil, billing)
+	assert.NoError(t, err)
+	cat, err := Aggregate(nil, sources)
+	assert.NoError(t, err)
+	ids := make(map[string][]string)
+	for _, e := range cat.Entries {
+		ids[e.ID] = nil
+		for _, l := range e.Links {
+			ids[e.ID] = append(ids[e.ID], l.ID)
+		}
+	}
+	assert.Equal(t, map[string][]string{
+		"billing:001":      {"billing:data/001"},
+		"billing:data/001": {"billing:001"},
+		"billing:SEC-001":  nil,
+	}, ids)
+	b := &bytes.Buffer{}
+	assert.NoError(t, cat.WriteMarkdown(b, workDir))
+	assert.Contains(t, b.String(), `<a id="billing-001"></a>`)
+	assert.Contains(t, b.String(), `<a id="billing-data-001"></a>`)
+}
+
+func Test_NameSources(t *testing.T) {
+	sources := []*Source{
+		{Dir: "/src/team-a/billing"},
+		{Dir: "/src/team-b/billing"},
+		{Dir: "/src/platform", Name: "core"},
+		{Dir: "/src/platform", Name: "edge"},
+	}
+	nameSources(sources)
+	var names []string
+	for _, s := range sources {
+		names = append(names, s.Name)
+	}
+	assert.Equal(t, []string{"team-a/billing", "team-b/billing", "platform/core", "platform/edge"}, names)
+}
@@ -0,0 +1,212 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"github.com/fleetingclarity/adr/storage"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Category groups records, e.g. security decisions, with their own sequence of numbers. Records of a category are
+// kept in its Directory of the repository, or named with its Prefix (e.g. SEC-007-use-mtls.md), or both.
+type Category struct {
+	Name      string `yaml:"name"`
+	Prefix    string `yaml:"prefix,omitempty"`    // letters and digits, written in upper case, e.g. SEC
+	Directory string `yaml:"directory,omitempty"` // relative to the repository, e.g. security
+}
+
+// categoryNamePattern is what the name of a category may be, so that references such as data-platform/7 name it
+var categoryNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*$`)
+
+// categoryPrefixPattern is what the prefix of a category may be, so that record IDs such as SEC-007 start with it
+var categoryPrefixPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9]*$`)
+
+// validateCategories reports a category that references or record IDs could not name, or whose records could not be
+// told apart from the uncategorized ones
+func (a *ADR) validateCategories() error {
+	if a == nil {
+		return nil
+	}
+	for _, c := range a.Categories {
+		if !categoryNamePattern.MatchString(c.Name) {
+			return fmt.Errorf("the category name '%s' must start with a letter followed by letters, digits, '-' or '_'", c.Name)
+		}
+		if c.Prefix == "" && c.Directory == "" {
+			return fmt.Errorf("the category %s needs a prefix or a directory to tell its records apart", c.Name)
+		}
+		if c.Prefix != "" && !categoryPrefixPattern.MatchString(c.Prefix) {
+			return fmt.Errorf("the prefix '%s' of category %s must start with a letter followed by letters or digits", c.Prefix, c.Name)
+		}
+	}
+	return nil
+}
+
+// idPattern matches record IDs such as '007' or 'SEC-007' at the start of a file name or heading
+var idPattern = regexp.MustCompile(`^(?:([A-Z][A-Z0-9]*)-)?(\d+)`)
+
+// splitID returns the category prefix, if any, and the number of an ID at the start of s
+func splitID(s string) (string, string) {
+	m := idPattern.FindStringSubmatch(s)
+	if m == nil {
+		return "", ""
+	}
+	return m[1], m[2]
+}
+
+// Category returns the category with the given name or prefix, or nil for the empty name
+func (a *ADR) Category(name string) (*Category, error) {
+	if name == "" {
+		return nil, nil
+	}
+	var names []string
+	for _, c := range a.Categories {
+		if strings.EqualFold(c.Name, name) || (c.Prefix != "" && strings.EqualFold(c.Prefix, name)) {
+			return c, nil
+		}
+		names = append(names, c.Name)
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("unknown category '%s', declare it under adr.categories in .adr.yaml", name)
+	}
+	return nil, fmt.Errorf("unknown category '%s', expected one of: %s", name, strings.Join(names, ", "))
+}
+
+// dir returns the directory records of category c are kept in, repoDir for uncategorized records
+func (c *Category) dir(repoDir string) string {
+	if c == nil || c.Directory == "" {
+		return repoDir
+	}
+	return filepath.Join(repoDir, c.Directory)
+}
+
+// id formats the ID of the nth record of category c, e.g. 'SEC-007'
+func (c *Category) id(n int) string {
+	if c == nil || c.Prefix == "" {
+		return fmt.Sprintf("%03d", n)
+	}
+	return fmt.Sprintf("%s-%03d", strings.ToUpper(c.Prefix), n)
+}
+
+// belongs reports whether the record at p is in category c of repoDir, a nil category holds the records without
+// a category prefix outside every category directory
+func (a *ADR) belongs(repoDir, p string, c *Category) bool {
+	prefix, _ := splitID(filepath.Base(p))
+	if c == nil {
+		if prefix != "" {
+			return false
+		}
+		for _, other := range a.Categories {
+			if other.Directory != "" && within(other.dir(repoDir), p) {
+				return false
+			}
+		}
+		return true
+	}
+	if !strings.EqualFold(prefix, c.Prefix) {
+		return false
+	}
+	return c.Directory == "" || within(c.dir(repoDir), p)
+}
+
+// within reports whether p is somewhere below dir
+func within(dir, p string) bool {
+	rel, err := filepath.Rel(dir, p)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// records returns the paths of the records of category c in repoDir with their numbers
+func (a *ADR) records(fsys storage.FS, repoDir string, c *Category) (map[string]int, error) {
+	fsys = fileSystem(fsys)
+	found := make(map[string]int)
+	root := c.dir(repoDir)
+	if _, err := fsys.Stat(root); errors.Is(err, fs.ErrNotExist) && root != repoDir {
+		return found, nil
+	}
+	err := storage.WalkDir(fsys, root, func(p string, info fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || filepath.Ext(p) != ".md" || !a.belongs(repoDir, p, c) {
+			return nil
+		}
+		if _, num := splitID(filepath.Base(p)); num != "" {
+			n, _ := strconv.Atoi(num)
+			found[p] = n
+		}
+		return nil
+	})
+	return found, err
+}
+
+// next returns the number of the next record in category c of repoDir
+func (a *ADR) next(fsys storage.FS, repoDir string, c *Category) (int, error) {
+	records, err := a.records(fsys, repoDir, c)
+	if err != nil {
+		return -1, err
+	}
+	highest := 0
+	for _, n := range records {
+		if n > highest {
+			highest = n
+		}
+	}
+	return highest + 1, nil
+}
+
+// FindRecord returns the record numbered num in category c of repoDir in fsys (the disk when nil), preferring a
+// record over a redirect stub. When several files share the number, e.g. after two branches each added one, the
+// first in path order is returned.
+func (a *ADR) FindRecord(fsys storage.FS, repoDir string, c *Category, num int) (string, error) {
+	records, err := a.records(fsys, repoDir, c)
+	if err != nil {
+		return "", err
+	}
+	var matches []string
+	for p, n := range records {
+		if n == num {
+			matches = append(matches, p)
+		}
+	}
+	if len(matches) == 0 {
+		return "", &NotFoundError{ID: c.id(num)}
+	}
+	sort.Strings(matches)
+	for _, p := range matches {
+		if !isRedirect(fsys, p) {
+			return p, nil
+		}
+	}
+	return matches[0], nil
+}
+
+// Locate returns the path of the record a reference points at, in its repository and category
+func (c *Config) Locate(ref Ref) (string, error) {
+	repoDir, a := c.Repository.Path, c.ADR
+	if ref.Repository != "" {
+		r, ra, err := c.Lookup(ref.Repository)
+		if err != nil {
+			return "", err
+		}
+		repoDir, a = r.Path, ra
+	}
+	cat, err := a.Category(ref.Category)
+	if err != nil {
+		return "", err
+	}
+	return a.FindRecord(c.FS, repoDir, cat, ref.Number)
+}
+
+// CategoryOf returns the category of the record at p in repoDir, nil when it has none
+func (a *ADR) CategoryOf(repoDir, p string) *Category {
+	for _, c := range a.Categories {
+		if a.belongs(repoDir, p, c) {
+			return c
+		}
+	}
+	return nil
+}
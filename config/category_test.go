@@ -0,0 +1,117 @@
+package config
+
+import (
+	"github.com/fleetingclarity/adr/storage"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+)
+
+func Test_CategoriesHaveTheirOwnSequences(t *testing.T) {
+	startDir, workDir, err := setup()
+	handleHarnessErr(t, err)
+	defer cleanup(startDir, workDir)
+	c := NewDefaultConfig()
+	c.Categories = []*Category{
+		{Name: "security", Prefix: "sec"},
+		{Name: "data", Directory: "data"},
+	}
+	repoDir := path.Join(workDir, DefaultRepositoryDir)
+	add := func(title, category string) {
+		handleHarnessErr(t, c.New(repoDir, map[string]string{"Title": title, "Category": category}))
+	}
+	// begin test
+	add("plain", "")
+	add("use mTLS", "security")
+	add("use postgres", "data")
+	add("rotate keys", "SEC")
+	add("second plain", "")
+	add("add redis", "data")
+	var files []string
+	handleHarnessErr(t, filepath.WalkDir(repoDir, func(p string, d os.DirEntry, err error) error {
+		if !d.IsDir() {
+			rel, _ := filepath.Rel(repoDir, p)
+			files = append(files, filepath.ToSlash(rel))
+		}
+		return err
+	}))
+	assert.ElementsMatch(t, []string{
+		"001-plain.md", "002-second-plain.md",
+		"SEC-001-use-mtls.md", "SEC-002-rotate-keys.md",
+		"data/001-use-postgres.md", "data/002-add-redis.md",
+	}, files)
+	b, err := os.ReadFile(path.Join(repoDir, "SEC-002-rotate-keys.md"))
+	assert.NoError(t, err)
+	r := ParseRecord(path.Join(repoDir, "SEC-002-rotate-keys.md"), b)
+	assert.Equal(t, "SEC-002", r.ID)
+	assert.Equal(t, 2, r.Number)
+	assert.Equal(t, "rotate keys", r.Title)
+	for _, f := range c.Lint(r) {
+		assert.NotEqual(t, "heading", f.Rule, "the prefixed heading matches the file")
+	}
+	assert.Equal(t, "security", c.CategoryOf(repoDir, r.Path).Name)
+	assert.Error(t, c.New(repoDir, map[string]string{"Title": "x", "Category": "finance"}))
+}
+
+func Test_LocateInCategories(t *testing.T) {
+	startDir, workDir, err := setup()
+	handleHarnessErr(t, err)
+	defer cleanup(startDir, workDir)
+	c := NewDefaultConfig()
+	c.Categories = []*Category{{Name: "security", Prefix: "SEC"}, {Name: "data", Directory: "data"}}
+	repoDir := path.Join(workDir, DefaultRepositoryDir)
+	c.Repository.Path = repoDir
+	for _, v := range []map[string]string{{"Title": "plain"}, {"Title": "mtls", "Category": "security"}, {"Title": "postgres", "Category": "data"}} {
+		handleHarnessErr(t, c.New(repoDir, v))
+	}
+	type test struct {
+		ref      string
+		expected string
+	}
+	tests := []test{
+		{ref: "1", expected: "001-plain.md"},
+		{ref: "SEC-1", expected: "SEC-001-mtls.md"},
+		{ref: "security/001", expected: "SEC-001-mtls.md"},
+		{ref: "data/1", expected: "data/001-postgres.md"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			ref, err := ParseRef(tt.ref)
+			assert.NoError(t, err)
+			p, err := c.Locate(ref)
+			assert.NoError(t, err)
+			assert.Equal(t, path.Join(repoDir, tt.expected), p)
+		})
+	}
+	_, err = c.Locate(Ref{Category: "data", Number: 2})
+	assert.Error(t, err)
+	handleHarnessErr(t, c.New(repoDir, map[string]string{"Title": "redis", "Category": "data"}))
+	_, err = c.Rename(repoDir, 2, "plain records", false)
+	var missing *NotFoundError
+	assert.ErrorAs(t, err, &missing, "records in category directories are not uncategorized records")
+	_, err = os.Stat(path.Join(repoDir, "data/002-redis.md"))
+	assert.NoError(t, err)
+	// linking across categories uses relative links that still parse
+	sp, _ := c.Locate(Ref{Category: "SEC", Number: 1})
+	tp, _ := c.Locate(Ref{Category: "data", Number: 1})
+	assert.NoError(t, Link(&LinkPair{SourcePath: sp, TargetPath: tp, SourceMsg: "needs", BackMsg: "needed by"}))
+	b, err := os.ReadFile(tp)
+	assert.NoError(t, err)
+	assert.Equal(t, "../SEC-001-mtls.md", ParseRecord(tp, b).Links[0].Target)
+}
+
+func Test_FindRecordWithDuplicateNumbers(t *testing.T) {
+	m := storage.NewMemory()
+	handleHarnessErr(t, m.MkdirAll("decisions", 0755))
+	handleHarnessErr(t, m.WriteFile("decisions/007-use-redis.md", []byte("# 007. Use Redis\n"), 0644))
+	handleHarnessErr(t, m.WriteFile("decisions/007-use-memcached.md", []byte("# 007. Use Memcached\n"), 0644))
+	handleHarnessErr(t, m.WriteFile("decisions/007-cache.md", []byte(redirectStub("007-use-redis.md")), 0644))
+	// begin test
+	for i := 0; i < 10; i++ {
+		p, err := NewDefaultConfig().FindRecord(m, "decisions", nil, 7)
+		assert.NoError(t, err)
+		assert.Equal(t, "decisions/007-use-memcached.md", p, "the first record in path order, skipping the stub")
+	}
+}
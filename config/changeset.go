@@ -0,0 +1,194 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"github.com/fleetingclarity/adr/storage"
+	"github.com/pmezard/go-difflib/difflib"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Changeset stages the file edits made by an operation in memory so they can be previewed as a diff or applied
+// together. Reads through a Changeset see the staged content, so several edits to the same file compose.
+type Changeset struct {
+	// Operation describes what the changes are for, it is recorded in the journal used by Undo
+	Operation string
+	// Journal is where Apply records the state needed to undo the changes, no journal is kept when it is empty
+	Journal string
+	// FS is where files are read from and written to, the disk when it is nil
+	FS    storage.FS
+	order []string
+	files map[string]*stagedFile
+}
+
+type stagedFile struct {
+	existed  bool
+	mode     os.FileMode
+	original []byte
+	removed  bool
+	content  []byte
+}
+
+// NewChangeset returns an empty Changeset
+func NewChangeset() *Changeset {
+	return &Changeset{files: make(map[string]*stagedFile)}
+}
+
+// fileSystem returns fsys, or the disk when it is nil
+func fileSystem(fsys storage.FS) storage.FS {
+	if fsys == nil {
+		return storage.OS
+	}
+	return fsys
+}
+
+// stage loads the on-disk state of p the first time it is touched
+func (c *Changeset) stage(p string) (*stagedFile, error) {
+	p = filepath.Clean(p)
+	if s, ok := c.files[p]; ok {
+		return s, nil
+	}
+	s := &stagedFile{mode: defaultFileMode}
+	fsys := fileSystem(c.FS)
+	b, err := fsys.ReadFile(p)
+	if err == nil {
+		s.existed = true
+		s.original = b
+		s.content = b
+		if fi, err := fsys.Stat(p); err == nil {
+			s.mode = fi.Mode().Perm()
+		}
+	} else if errors.Is(err, os.ErrNotExist) {
+		s.removed = true
+	} else {
+		return nil, err
+	}
+	c.files[p] = s
+	c.order = append(c.order, p)
+	return s, nil
+}
+
+// Read returns the staged content of p, falling back to the file on disk
+func (c *Changeset) Read(p string) ([]byte, error) {
+	s, err := c.stage(p)
+	if err != nil {
+		return nil, err
+	}
+	if s.removed {
+		return nil, &os.PathError{Op: "read", Path: p, Err: os.ErrNotExist}
+	}
+	return s.content, nil
+}
+
+// Exists reports whether p exists once the staged changes are taken into account
+func (c *Changeset) Exists(p string) bool {
+	s, err := c.stage(p)
+	return err == nil && !s.removed
+}
+
+// Write stages new content for p, creating it if necessary
+func (c *Changeset) Write(p string, b []byte) error {
+	s, err := c.stage(p)
+	if err != nil {
+		return err
+	}
+	s.removed = false
+	s.content = b
+	return nil
+}
+
+// writeAs stages new content for p like Write, giving p the mode of src when it is created, e.g. for the new name of
+// a renamed record
+func (c *Changeset) writeAs(p, src string, b []byte) error {
+	from, err := c.stage(src)
+	if err != nil {
+		return err
+	}
+	s, err := c.stage(p)
+	if err != nil {
+		return err
+	}
+	if !s.existed {
+		s.mode = from.mode
+	}
+	return c.Write(p, b)
+}
+
+// Remove stages the deletion of p
+func (c *Changeset) Remove(p string) error {
+	s, err := c.stage(p)
+	if err != nil {
+		return err
+	}
+	if s.removed {
+		return &os.PathError{Op: "remove", Path: p, Err: os.ErrNotExist}
+	}
+	s.removed = true
+	s.content = nil
+	return nil
+}
+
+// rewrite runs edit over the staged content of p and stages the result
+func (c *Changeset) rewrite(p string, edit func(in []byte) ([]byte, error)) error {
+	b, err := c.Read(p)
+	if err != nil {
+		return err
+	}
+	out, err := edit(b)
+	if err != nil {
+		return err
+	}
+	return c.Write(p, out)
+}
+
+// changed reports whether the staged state of a file differs from what is on disk
+func (s *stagedFile) changed() bool {
+	if s.existed == s.removed {
+		return true
+	}
+	return !bytes.Equal(s.original, s.content)
+}
+
+// Paths returns the files that the staged changes would create, modify or remove, in the order they were touched
+func (c *Changeset) Paths() []string {
+	var paths []string
+	for _, p := range c.order {
+		if c.files[p].changed() {
+			paths = append(paths, p)
+		}
+	}
+	return paths
+}
+
+// Diff writes a unified diff of every staged change to w
+func (c *Changeset) Diff(w io.Writer) error {
+	for _, p := range c.Paths() {
+		s := c.files[p]
+		from, to := "a/"+filepath.ToSlash(p), "b/"+filepath.ToSlash(p)
+		if !s.existed {
+			from = "/dev/null"
+		}
+		if s.removed {
+			to = "/dev/null"
+		}
+		d := difflib.UnifiedDiff{
+			A:        difflib.SplitLines(string(s.original)),
+			B:        difflib.SplitLines(string(s.content)),
+			FromFile: from,
+			ToFile:   to,
+			Context:  3,
+		}
+		if !s.existed {
+			d.A = nil
+		}
+		if s.removed {
+			d.B = nil
+		}
+		if err := difflib.WriteUnifiedDiff(w, d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
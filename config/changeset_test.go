@@ -0,0 +1,53 @@
+package config
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path"
+	"testing"
+)
+
+func Test_ChangesetDiffLeavesFilesUntouched(t *testing.T) {
+	startDir, workDir, err := setup()
+	handleHarnessErr(t, err)
+	defer cleanup(startDir, workDir)
+	c := NewDefaultConfig()
+	repoDir := path.Join(workDir, DefaultRepositoryDir)
+	handleHarnessErr(t, c.New(repoDir, map[string]string{"Title": "first"}))
+	handleHarnessErr(t, c.New(repoDir, map[string]string{"Title": "second"}))
+	before, err := os.ReadFile(path.Join(repoDir, "001-first.md"))
+	handleHarnessErr(t, err)
+	// begin test
+	cs := NewChangeset()
+	err = cs.Supersede(&LinkPair{SourceNum: 1, TargetNum: 2, SourceMsg: "note", RepoDir: repoDir})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{path.Join(repoDir, "001-first.md"), path.Join(repoDir, "002-second.md")}, cs.Paths())
+	out := &bytes.Buffer{}
+	assert.NoError(t, cs.Diff(out))
+	diff := out.String()
+	assert.Contains(t, diff, "--- a/"+path.Join(repoDir, "001-first.md"))
+	assert.Contains(t, diff, "-Proposed\n+Superseded\n")
+	assert.Contains(t, diff, "+[Superseded by 002-second.md: note](./002-second.md)\n")
+	assert.Contains(t, diff, "+[Supersedes 001-first.md](./001-first.md)\n")
+	after, err := os.ReadFile(path.Join(repoDir, "001-first.md"))
+	assert.NoError(t, err)
+	assert.Equal(t, string(before), string(after), "a diff must not modify the file on disk")
+}
+
+func Test_ChangesetDiffNewFile(t *testing.T) {
+	startDir, workDir, err := setup()
+	handleHarnessErr(t, err)
+	defer cleanup(startDir, workDir)
+	c := NewDefaultConfig()
+	// begin test
+	cs := NewChangeset()
+	p, err := cs.New(c.ADR, DefaultRepositoryDir, map[string]string{"Title": "first"})
+	assert.NoError(t, err)
+	out := &bytes.Buffer{}
+	assert.NoError(t, cs.Diff(out))
+	assert.Contains(t, out.String(), "--- /dev/null\n+++ b/"+p)
+	assert.Contains(t, out.String(), "+# 001. first\n")
+	_, err = os.Stat(p)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -3,10 +3,13 @@ package config
 import (
 	"errors"
 	"fmt"
+	"github.com/fleetingclarity/adr/storage"
 	"gopkg.in/yaml.v3"
 	"io"
 	"os"
 	"path"
+	"strconv"
+	"strings"
 )
 
 const (
@@ -19,8 +22,15 @@ type Config struct {
 	WorkingDirectory string `yaml:"-"`
 	CfgFileName      string `yaml:"-"`
 	CfgFileExt       string `yaml:"-"`
-	*Repository
-	*ADR
+	*Repository      `yaml:"repository,omitempty"`
+	// Repositories are named ADR repositories kept alongside (or instead of) the default one, e.g. one per service
+	Repositories []*Repository `yaml:"repositories,omitempty"`
+	*ADR         `yaml:"adr,omitempty"`
+	Git          *Git `yaml:"git,omitempty"`
+	// FS is where records are looked up, the disk when it is nil
+	FS storage.FS `yaml:"-"`
+	// selected is set once Use picked a repository
+	selected *selection
 }
 
 // EnsureRepositoryExists creates the repository directory if it doesn't exist. ADRs will be stored in this directory
@@ -50,6 +60,12 @@ func (c *Config) CreateAndWrite() error {
 
 // Write writes the current struct to the given io.Writer
 func (c *Config) Write(w io.Writer) error {
+	if c.selected != nil {
+		// write the configuration as read rather than the repository in use
+		read := *c
+		read.Repository, read.ADR, read.selected = c.selected.repository, c.selected.base, nil
+		c = &read
+	}
 	o, err := yaml.Marshal(c)
 	if err != nil {
 		return err
@@ -86,3 +102,124 @@ func NewDefaultConfig() *Config {
 		},
 	}
 }
+
+// setYAMLValue returns the YAML document in with the scalar at the given keys set to value, where the keys of
+// sequences are indexes. An existing value is replaced in place and a missing one is added as new lines next to a
+// sibling, so the rest of the document is kept byte for byte. Documents laid out in ways that cannot be edited line
+// by line, e.g. with flow mappings, are re-encoded from their nodes, which still keeps their comments and key order.
+func setYAMLValue(in []byte, keys []string, value string) ([]byte, error) {
+	doc := &yaml.Node{}
+	if err := yaml.Unmarshal(in, doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		doc.Kind, doc.Content = yaml.DocumentNode, []*yaml.Node{{Kind: yaml.MappingNode}}
+	}
+	lines := strings.SplitAfter(string(in), "\n")
+	n := doc.Content[0]
+	for i, key := range keys {
+		if n.Kind == yaml.SequenceNode {
+			idx, err := strconv.Atoi(key)
+			if err != nil || idx >= len(n.Content) {
+				return nil, fmt.Errorf("%s is not in the configuration", strings.Join(keys[:i+1], "."))
+			}
+			n = n.Content[idx]
+			continue
+		}
+		if n.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("%s is not a mapping in the configuration", strings.Join(keys[:i], "."))
+		}
+		v := frontMatterValue(n, key)
+		if v == nil {
+			return insertYAMLValue(doc, lines, n, keys[i:], value)
+		}
+		if i == len(keys)-1 {
+			if v.Kind != yaml.ScalarNode {
+				return nil, fmt.Errorf("%s is not a value in the configuration", strings.Join(keys, "."))
+			}
+			return replaceYAMLScalar(doc, lines, v, value)
+		}
+		n = v
+	}
+	return nil, fmt.Errorf("%s is not a value in the configuration", strings.Join(keys, "."))
+}
+
+// replaceYAMLScalar replaces the text of scalar v in lines with value, in the same quoting style
+func replaceYAMLScalar(doc *yaml.Node, lines []string, v *yaml.Node, value string) ([]byte, error) {
+	var old, quote string
+	switch v.Style {
+	case 0:
+		old = v.Value
+	case yaml.DoubleQuotedStyle:
+		old, quote = `"`+v.Value+`"`, `"`
+	case yaml.SingleQuotedStyle:
+		old, quote = "'"+v.Value+"'", "'"
+	}
+	if old == "" || v.Line > len(lines) || !strings.HasPrefix(lines[v.Line-1][v.Column-1:], old) {
+		v.Value, v.Tag, v.Style = value, "", 0
+		return yaml.Marshal(doc)
+	}
+	line := lines[v.Line-1]
+	lines[v.Line-1] = line[:v.Column-1] + quote + value + quote + line[v.Column-1+len(old):]
+	return []byte(strings.Join(lines, "")), nil
+}
+
+// insertYAMLValue adds the nested keys, with value for the last one, to mapping m. The new lines follow the first
+// key of m that has a value on its own line, indented like it.
+func insertYAMLValue(doc *yaml.Node, lines []string, m *yaml.Node, keys []string, value string) ([]byte, error) {
+	after := -1
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		k, v := m.Content[i], m.Content[i+1]
+		if v.Kind == yaml.ScalarNode && v.Style&(yaml.LiteralStyle|yaml.FoldedStyle) == 0 && k.Line == v.Line {
+			after = i
+			break
+		}
+	}
+	if m.Style&yaml.FlowStyle != 0 || after < 0 || m.Content[after].Line > len(lines) ||
+		!strings.HasSuffix(lines[m.Content[after].Line-1], "\n") {
+		nested := &yaml.Node{Kind: yaml.ScalarNode, Value: value}
+		for i := len(keys) - 1; i > 0; i-- {
+			nested = &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{{Kind: yaml.ScalarNode, Value: keys[i]}, nested}}
+		}
+		m.Content = append(m.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: keys[0]}, nested)
+		return yaml.Marshal(doc)
+	}
+	sibling := m.Content[after]
+	step := yamlIndent(doc)
+	if step == 0 {
+		// the indentation Write uses
+		step = 4
+	}
+	indent := strings.Repeat(" ", sibling.Column-1)
+	var added []string
+	for i, key := range keys {
+		if i == len(keys)-1 {
+			added = append(added, indent+key+": "+value+"\n")
+		} else {
+			added = append(added, indent+key+":\n")
+			indent += strings.Repeat(" ", step)
+		}
+	}
+	at := sibling.Line
+	out := append(append(append([]string{}, lines[:at]...), added...), lines[at:]...)
+	return []byte(strings.Join(out, "")), nil
+}
+
+// yamlIndent returns how far the first nested block mapping or sequence of node n is indented, 0 when there is none
+func yamlIndent(n *yaml.Node) int {
+	for i := 0; n.Kind == yaml.MappingNode && i+1 < len(n.Content); i += 2 {
+		k, v := n.Content[i], n.Content[i+1]
+		if v.Kind == yaml.MappingNode && v.Style&yaml.FlowStyle == 0 && len(v.Content) > 0 && v.Content[0].Line > k.Line {
+			return v.Content[0].Column - k.Column
+		}
+		if v.Kind == yaml.SequenceNode && v.Style&yaml.FlowStyle == 0 && v.Line > k.Line && v.Column > k.Column {
+			return v.Column - k.Column
+		}
+	}
+	for _, c := range n.Content {
+		if indent := yamlIndent(c); indent > 0 {
+			return indent
+		}
+	}
+	return 0
+}
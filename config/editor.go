@@ -0,0 +1,43 @@
+package config
+
+import (
+	"errors"
+	"github.com/fleetingclarity/adr/storage"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Editor returns the command line of the user's editor: $VISUAL, or $EDITOR when that is not set. The value may
+// include arguments, e.g. 'code --wait'.
+func Editor() ([]string, error) {
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if args := strings.Fields(os.Getenv(env)); len(args) > 0 {
+			return args, nil
+		}
+	}
+	return nil, errors.New("no editor configured, set $VISUAL or $EDITOR")
+}
+
+// Edit opens p in the user's editor attached to the given terminal streams and waits for it to exit
+func Edit(p string, stdin io.Reader, stdout, stderr io.Writer) error {
+	args, err := Editor()
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(args[0], append(args[1:], p)...)
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	return cmd.Run()
+}
+
+// LintFile parses the record at p of fsys (the disk when nil) and lints it, as after it has been edited
+func (a *ADR) LintFile(fsys storage.FS, p string) ([]Finding, error) {
+	b, err := fileSystem(fsys).ReadFile(p)
+	if err != nil {
+		return nil, err
+	}
+	return a.Lint(ParseRecord(p, b)), nil
+}
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"os/exec"
+	"path"
+	"testing"
+)
+
+func Test_Editor(t *testing.T) {
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "")
+	_, err := Editor()
+	assert.Error(t, err, "there is nothing to open")
+	t.Setenv("EDITOR", "vi")
+	args, err := Editor()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"vi"}, args)
+	t.Setenv("VISUAL", "code --wait")
+	args, err = Editor()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"code", "--wait"}, args, "VISUAL wins and may carry arguments")
+}
+
+func Test_EditThenLint(t *testing.T) {
+	if _, err := exec.LookPath("cp"); err != nil {
+		t.Skip("cp is not available")
+	}
+	startDir, workDir, err := setup()
+	handleHarnessErr(t, err)
+	defer cleanup(startDir, workDir)
+	c := NewDefaultConfig()
+	repoDir := path.Join(workDir, DefaultRepositoryDir)
+	handleHarnessErr(t, c.New(repoDir, map[string]string{"Title": "first"}))
+	p := path.Join(repoDir, "001-first.md")
+	edited := path.Join(workDir, "edited.md")
+	handleHarnessErr(t, os.WriteFile(edited, []byte("# 001-first\n\n## Status\nProposed\n\n## Context\nOurs.\n\n## Decision\nOurs.\n\n## Consequences\nOurs.\n"), 0644))
+	// the 'editor' replaces the record with the edited text
+	t.Setenv("VISUAL", "cp "+edited)
+	// begin test
+	findings, err := c.LintFile(nil, p)
+	assert.NoError(t, err)
+	assert.Len(t, findings, 3, "a new record still has the template text in every section")
+	assert.NoError(t, Edit(p, nil, nil, nil))
+	findings, err = c.LintFile(nil, p)
+	assert.NoError(t, err)
+	assert.Empty(t, findings)
+}
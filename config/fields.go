@@ -0,0 +1,167 @@
+package config
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"strings"
+)
+
+// Field types supported in the metadata schema
+const (
+	FieldString = "string"
+	FieldEnum   = "enum"
+	FieldList   = "list"
+	FieldDate   = "date"
+)
+
+// Field declares a custom metadata field for records. Fields are template variables named after the field, they are
+// written to the metadata lines under the heading (e.g. 'Cost-Tier: low') and validated by lint. A name with a dash
+// is not a template identifier, so templates render such a field with {{ index . "cost-tier" }}, not {{ .cost-tier }}.
+type Field struct {
+	Name     string   // the template variable and metadata key, e.g. cost-tier
+	Type     string   // string (default), enum, list or date
+	Values   []string // allowed values for an enum, or for the items of a list when given
+	Required bool     // lint reports records without a value
+	Default  string   // used by add when no value is given
+}
+
+// Label is how the field is written in a record, e.g. 'Cost-Tier'
+func (f *Field) Label() string {
+	return labelFor(f.Name)
+}
+
+// Validate reports whether value is acceptable for the field, an empty value is only an error for required fields.
+// Dates are read with the given layout, the configured date format, before the known ones.
+func (f *Field) Validate(value, layout string) error {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		if f.Required {
+			return fmt.Errorf("%s is required", f.Name)
+		}
+		return nil
+	}
+	switch f.Type {
+	case "", FieldString:
+		return nil
+	case FieldEnum:
+		if !containsFold(f.Values, value) {
+			return fmt.Errorf("%s must be one of %s, not '%s'", f.Name, strings.Join(f.Values, ", "), value)
+		}
+	case FieldList:
+		for _, item := range SplitList(value) {
+			if len(f.Values) > 0 && !containsFold(f.Values, item) {
+				return fmt.Errorf("%s items must be from %s, not '%s'", f.Name, strings.Join(f.Values, ", "), item)
+			}
+		}
+	case FieldDate:
+		if _, ok := ParseDate(value, layout); !ok {
+			return fmt.Errorf("%s must be a date like %s, not '%s'", f.Name, layout, value)
+		}
+	default:
+		return fmt.Errorf("%s has unknown type '%s', expected string, enum, list or date", f.Name, f.Type)
+	}
+	return nil
+}
+
+// SplitList splits a comma separated list value into its trimmed, non-empty items
+func SplitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Field returns the declared field with the given name or nil
+func (a *ADR) Field(name string) *Field {
+	return fieldNamed(a.Fields, name)
+}
+
+func fieldNamed(fields []*Field, name string) *Field {
+	for _, f := range fields {
+		if strings.EqualFold(f.Name, name) {
+			return f
+		}
+	}
+	return nil
+}
+
+// ParseSet parses 'key=value' assignments, as given to add --set, into values after checking them against the schema
+func (a *ADR) ParseSet(assignments []string, values map[string]string) error {
+	for _, s := range assignments {
+		key, value, found := strings.Cut(s, "=")
+		if !found {
+			return fmt.Errorf("expected key=value, not '%s'", s)
+		}
+		f := a.Field(strings.TrimSpace(key))
+		if f == nil {
+			return fmt.Errorf("unknown field '%s', declare it under adr.fields in .adr.yaml", key)
+		}
+		if err := f.Validate(value, a.DateLayout()); err != nil {
+			return err
+		}
+		values[f.Name] = strings.TrimSpace(value)
+	}
+	return nil
+}
+
+// HasField reports whether the record's value for the named metadata field matches value. List values match when
+// any of their items does.
+func (r *Record) HasField(name, value string) bool {
+	v, ok := r.Metadata[strings.ToLower(name)]
+	if !ok {
+		return false
+	}
+	if strings.EqualFold(v, value) {
+		return true
+	}
+	return containsFold(SplitList(v), value)
+}
+
+// metadataLine matches 'Key: value' lines in the preamble between the heading and the first section
+func metadataLine(line string) (string, string, bool) {
+	key, value, found := strings.Cut(line, ":")
+	if !found || key == "" || !isFieldName(key) {
+		return "", "", false
+	}
+	return strings.ToLower(key), strings.TrimSpace(value), true
+}
+
+// setMetadata writes 'Label: value' into the preamble of a record, replacing an existing line for the same key or
+// adding one after the last metadata line (or the heading). Records with front matter get the lower case label as
+// a front matter key instead, front matter that does not parse is an error.
+func setMetadata(in []byte, label, value string) ([]byte, error) {
+	key := strings.ToLower(label)
+	if hasFrontMatter(in) {
+		return editFrontMatter(in, func(m *yaml.Node) error {
+			return setFrontMatterValue(m, key, value)
+		})
+	}
+	return editLines(in, func(lines []string) []string {
+		insertAt := -1
+		for i, line := range lines {
+			if strings.HasPrefix(line, "## ") {
+				break
+			}
+			if strings.HasPrefix(line, "# ") && insertAt < 0 {
+				insertAt = i + 1
+				continue
+			}
+			if k, _, ok := metadataLine(line); ok {
+				if k == key {
+					lines[i] = label + ": " + value
+					return lines
+				}
+				insertAt = i + 1
+			}
+		}
+		if insertAt < 0 {
+			insertAt = 0
+		}
+		out := append([]string{}, lines[:insertAt]...)
+		out = append(out, label+": "+value)
+		return append(out, lines[insertAt:]...)
+	}), nil
+}
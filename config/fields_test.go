@@ -0,0 +1,103 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path"
+	"testing"
+)
+
+func Test_FieldValidation(t *testing.T) {
+	type test struct {
+		name  string
+		field Field
+		value string
+		valid bool
+	}
+	enum := Field{Name: "cost-tier", Type: FieldEnum, Values: []string{"low", "high"}}
+	tests := []test{
+		{name: "String accepts anything", field: Field{Name: "owner"}, value: "anyone", valid: true},
+		{name: "Enum accepts a listed value", field: enum, value: "High", valid: true},
+		{name: "Enum rejects others", field: enum, value: "mid", valid: false},
+		{name: "List with allowed items", field: Field{Name: "tags", Type: FieldList, Values: []string{"a", "b"}}, value: "a, b", valid: true},
+		{name: "List with unknown item", field: Field{Name: "tags", Type: FieldList, Values: []string{"a", "b"}}, value: "a,c", valid: false},
+		{name: "Date", field: Field{Name: "review-by", Type: FieldDate}, value: "2027-01-31", valid: true},
+		{name: "Bad date", field: Field{Name: "review-by", Type: FieldDate}, value: "next year", valid: false},
+		{name: "Date in the configured format", field: Field{Name: "review-by", Type: FieldDate}, value: "31.01.2027", valid: true},
+		{name: "Optional may be empty", field: enum, value: "", valid: true},
+		{name: "Required may not", field: Field{Name: "owner", Required: true}, value: " ", valid: false},
+		{name: "Unknown type", field: Field{Name: "owner", Type: "number"}, value: "1", valid: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.field.Validate(tt.value, "02.01.2006")
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func Test_NewWritesDeclaredFields(t *testing.T) {
+	startDir, workDir, err := setup()
+	handleHarnessErr(t, err)
+	defer cleanup(startDir, workDir)
+	c := NewDefaultConfig()
+	c.Fields = []*Field{
+		{Name: "tags", Type: FieldList},
+		{Name: "cost-tier", Type: FieldEnum, Values: []string{"low", "high"}, Default: "low"},
+		{Name: "owner"},
+	}
+	repoDir := path.Join(workDir, DefaultRepositoryDir)
+	values := map[string]string{"Title": "first"}
+	handleHarnessErr(t, c.ParseSet([]string{"tags=billing, data"}, values))
+	// begin test
+	assert.NoError(t, c.New(repoDir, values))
+	b, err := os.ReadFile(path.Join(repoDir, "001-first.md"))
+	assert.NoError(t, err)
+	r := ParseRecord("001-first.md", b)
+	assert.Equal(t, "billing, data", r.Metadata["tags"])
+	assert.Equal(t, "low", r.Metadata["cost-tier"], "defaults should be written")
+	assert.NotContains(t, r.Metadata, "owner", "empty optional fields are left out")
+	assert.True(t, r.HasField("tags", "data"))
+	assert.Contains(t, string(b), "Date: ", "existing metadata is kept")
+	assert.Error(t, c.ParseSet([]string{"colour=blue"}, values), "undeclared fields are rejected")
+	assert.Error(t, c.ParseSet([]string{"cost-tier=mid"}, values), "invalid values are rejected")
+
+	c.BodyTemplate = "# {{ .Number }}. {{ .Title }}\nCost: {{ index . \"cost-tier\" }}\n\n## Status\n{{ .Status }}\n"
+	assert.NoError(t, c.New(repoDir, map[string]string{"Title": "second", "cost-tier": "high"}))
+	b, err = os.ReadFile(path.Join(repoDir, "002-second.md"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), "Cost: high\n", "fields named with a dash are rendered with index")
+
+	c.BodyTemplate = "---\ntitle: [unclosed\n---\n# {{ .Number }}. {{ .Title }}\n"
+	assert.Error(t, c.New(repoDir, map[string]string{"Title": "third", "owner": "alice"}),
+		"fields are not written into the body of a record whose front matter does not parse")
+}
+
+func Test_Lint(t *testing.T) {
+	c := NewDefaultConfig()
+	c.Fields = []*Field{{Name: "cost-tier", Type: FieldEnum, Values: []string{"low", "high"}, Required: true}}
+	type test struct {
+		name     string
+		contents string
+		rules    []string
+	}
+	tests := []test{
+		{name: "Valid", contents: "# 001-x\nCost-Tier: low\n\n## Status\nAccepted\n\n## Context\n\n## Decision\n\n## Consequences\n", rules: nil},
+		{name: "Missing status and sections", contents: "# 001-x\nCost-Tier: low\n\n## Context\n", rules: []string{"status", "section", "section", "section"}},
+		{name: "Bad field and wrong number", contents: "# 002-x\nCost-Tier: mid\n\n## Status\nAccepted\n\n## Context\n\n## Decision\n\n## Consequences\n", rules: []string{"heading", "field"}},
+		{name: "No heading", contents: "Cost-Tier: low\n## Status\n\n## Context\n\n## Decision\n\n## Consequences\n", rules: []string{"heading", "status"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var rules []string
+			for _, f := range c.Lint(ParseRecord("001-x.md", []byte(tt.contents))) {
+				rules = append(rules, f.Rule)
+			}
+			assert.Equal(t, tt.rules, rules)
+		})
+	}
+}
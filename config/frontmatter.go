@@ -0,0 +1,439 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Metadata styles for records
+const (
+	// MetadataSections keeps status and links in the '## Status' section and other metadata in 'Key: value' lines
+	MetadataSections = "sections"
+	// MetadataFrontMatter keeps status, date, id, fields and links in YAML front matter
+	MetadataFrontMatter = "frontmatter"
+)
+
+const frontMatterDelimiter = "---"
+
+// Relation is a link from one record to another
+type Relation struct {
+	Rel     string `yaml:"rel" json:"rel"`
+	Target  string `yaml:"target" json:"target"`
+	Message string `yaml:"message,omitempty" json:"message,omitempty"`
+}
+
+// markdown renders the relation the way the Status section has always written links
+func (l Relation) markdown() string {
+	msg := ""
+	if l.Message != "" {
+		msg = ": " + l.Message
+	}
+	return fmt.Sprintf("[%s %s%s](%s)", l.Rel, l.Target, msg, href(l.Target))
+}
+
+// splitFrontMatter returns the lines between the leading '---' delimiters and the index of the first body line
+func splitFrontMatter(lines []string) ([]string, int, bool) {
+	if len(lines) == 0 || lines[0] != frontMatterDelimiter {
+		return nil, 0, false
+	}
+	for i := 1; i < len(lines); i++ {
+		if lines[i] == frontMatterDelimiter {
+			return lines[1:i], i + 1, true
+		}
+	}
+	return nil, 0, false
+}
+
+// hasFrontMatter reports whether a record keeps its metadata in front matter
+func hasFrontMatter(in []byte) bool {
+	lines, _ := splitLines(in)
+	_, _, ok := splitFrontMatter(lines)
+	return ok
+}
+
+// parseFrontMatter decodes front matter lines into a mapping node, keeping key order and unknown keys
+func parseFrontMatter(lines []string) (*yaml.Node, error) {
+	doc := &yaml.Node{}
+	if err := yaml.Unmarshal([]byte(strings.Join(lines, "\n")), doc); err != nil {
+		return nil, fmt.Errorf("unable to read the front matter: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		return &yaml.Node{Kind: yaml.MappingNode}, nil
+	}
+	if doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("the front matter must be a mapping of keys to values")
+	}
+	return doc.Content[0], nil
+}
+
+// encodeFrontMatter renders a mapping node as front matter lines, including the delimiters
+func encodeFrontMatter(m *yaml.Node) ([]string, error) {
+	b := &bytes.Buffer{}
+	e := yaml.NewEncoder(b)
+	e.SetIndent(2)
+	if err := e.Encode(m); err != nil {
+		return nil, err
+	}
+	if err := e.Close(); err != nil {
+		return nil, err
+	}
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+	if len(m.Content) == 0 {
+		lines = nil
+	}
+	return append(append([]string{frontMatterDelimiter}, lines...), frontMatterDelimiter), nil
+}
+
+// frontMatterValue returns the node for key in mapping m or nil
+func frontMatterValue(m *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// setFrontMatterValue sets key in mapping m to value, keeping the position of an existing key
+func setFrontMatterValue(m *yaml.Node, key string, value interface{}) error {
+	v := &yaml.Node{}
+	if err := v.Encode(value); err != nil {
+		return err
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			m.Content[i+1] = v
+			return nil
+		}
+	}
+	m.Content = append(m.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, v)
+	return nil
+}
+
+// removeFrontMatterValue deletes key from mapping m
+func removeFrontMatterValue(m *yaml.Node, key string) {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			m.Content = append(m.Content[:i], m.Content[i+2:]...)
+			return
+		}
+	}
+}
+
+// editFrontMatter runs edit over the front matter of a record and writes it back, creating it when missing
+func editFrontMatter(in []byte, edit func(m *yaml.Node) error) ([]byte, error) {
+	var err error
+	out := editLines(in, func(lines []string) []string {
+		fm, bodyStart, _ := splitFrontMatter(lines)
+		var m *yaml.Node
+		if m, err = parseFrontMatter(fm); err != nil {
+			return lines
+		}
+		if err = edit(m); err != nil {
+			return lines
+		}
+		var encoded []string
+		if encoded, err = encodeFrontMatter(m); err != nil {
+			return lines
+		}
+		return append(encoded, lines[bodyStart:]...)
+	})
+	return out, err
+}
+
+// frontMatterRelations decodes the links of a front matter mapping
+func frontMatterRelations(m *yaml.Node) []Relation {
+	var links []Relation
+	if n := frontMatterValue(m, "links"); n != nil {
+		_ = n.Decode(&links)
+	}
+	return links
+}
+
+// frontMatterString returns a scalar value as a string and a sequence as a comma separated list
+func frontMatterString(n *yaml.Node) string {
+	switch n.Kind {
+	case yaml.ScalarNode:
+		return n.Value
+	case yaml.SequenceNode:
+		var items []string
+		for _, item := range n.Content {
+			if item.Kind == yaml.ScalarNode {
+				items = append(items, item.Value)
+			}
+		}
+		return strings.Join(items, ", ")
+	}
+	return ""
+}
+
+// relationPattern matches the links written into the Status section, to a record alongside ('./012-x.md') or in
+// another repository ('../billing/012-x.md')
+var relationPattern = regexp.MustCompile(`^\[(.+)\]\((?:\./([^)]+)|(\.\./[^)]+))\)$`)
+
+// adrToolsRelationPattern matches the links adr-tools writes, e.g. 'Superseded by [2. Use X](0002-use-x.md)'
+var adrToolsRelationPattern = regexp.MustCompile(`^([A-Za-z][^\[]*?)\s+\[[^\]]+\]\(([^):]+\.md)\)$`)
+
+// parseRelation reads a link like '[Supersedes 001-a.md: note](./001-a.md)' from the Status section
+func parseRelation(line string) (Relation, bool) {
+	m := relationPattern.FindStringSubmatch(strings.TrimSpace(line))
+	if m == nil {
+		if m = adrToolsRelationPattern.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+			return Relation{Rel: m[1], Target: m[2]}, true
+		}
+		return Relation{}, false
+	}
+	text, target := m[1], m[2]+m[3]
+	i := strings.Index(text, target)
+	if i < 0 {
+		return Relation{Rel: text, Target: target}, true
+	}
+	return Relation{
+		Rel:     strings.TrimSpace(text[:i]),
+		Target:  target,
+		Message: strings.TrimSpace(strings.TrimPrefix(text[i+len(target):], ":")),
+	}, true
+}
+
+// labelFor turns a metadata key like 'cost-tier' into the label written in sections style, e.g. 'Cost-Tier'
+func labelFor(key string) string {
+	parts := strings.Split(key, "-")
+	for i, p := range parts {
+		if p != "" {
+			parts[i] = strings.ToUpper(p[:1]) + p[1:]
+		}
+	}
+	return strings.Join(parts, "-")
+}
+
+// toFrontMatter moves the metadata lines, status and links of a sections style record into front matter. Any
+// other text in the Status section is left where it is.
+func toFrontMatter(in []byte, id string, fields []*Field) ([]byte, error) {
+	if hasFrontMatter(in) {
+		return in, nil
+	}
+	r := ParseRecord("", in)
+	m := &yaml.Node{Kind: yaml.MappingNode}
+	set := func(key string, value interface{}) {
+		_ = setFrontMatterValue(m, key, value)
+	}
+	if n, err := strconv.Atoi(id); err == nil {
+		set("id", n)
+	} else {
+		set("id", id)
+	}
+	set("title", r.Title)
+	set("status", r.Status)
+	for _, key := range r.metadataKeys() {
+		if isListKey(fields, key) {
+			set(key, SplitList(r.Metadata[key]))
+		} else {
+			set(key, r.Metadata[key])
+		}
+	}
+	if len(r.Links) > 0 {
+		set("links", r.Links)
+	}
+	review := r.Section(reviewSection)
+	if len(r.Reviews) > 0 {
+		set("reviews", r.Reviews)
+	} else {
+		review = nil
+	}
+	encoded, err := encodeFrontMatter(m)
+	if err != nil {
+		return nil, err
+	}
+	return editLines(in, func(lines []string) []string {
+		out := append([]string{}, encoded...)
+		status := r.Section("Status")
+		statusWritten := false
+		for i, line := range lines {
+			n := i + 1
+			if _, isMeta := r.metadataLineSet()[n]; isMeta {
+				continue
+			}
+			if status != nil && n >= status.Line && n <= status.Line+len(status.lines) {
+				if !statusWritten {
+					out = append(out, status.remainder(r.Status)...)
+					statusWritten = true
+				}
+				continue
+			}
+			if review != nil && n >= review.Line && n <= review.Line+len(review.lines) {
+				if n == review.Line {
+					out = append(out, review.withoutReviews()...)
+				}
+				continue
+			}
+			out = append(out, line)
+		}
+		return out
+	}), nil
+}
+
+// remainder returns the Status section without its status and links, or nothing if that leaves it empty
+func (s *Section) remainder(status string) []string {
+	var kept []string
+	statusSeen := false
+	for _, line := range s.lines {
+		if !statusSeen && strings.TrimSpace(line) == status && status != "" {
+			statusSeen = true
+			continue
+		}
+		if _, ok := parseRelation(line); ok {
+			continue
+		}
+		kept = append(kept, line)
+	}
+	if strings.TrimSpace(strings.Join(kept, "")) == "" {
+		return nil
+	}
+	return append([]string{"## " + s.Name}, kept...)
+}
+
+// toSections moves the front matter of a record back into metadata lines under the heading and a Status section
+func toSections(in []byte) ([]byte, error) {
+	lines, _ := splitLines(in)
+	fm, bodyStart, ok := splitFrontMatter(lines)
+	if !ok {
+		return in, nil
+	}
+	m, err := parseFrontMatter(fm)
+	if err != nil {
+		return nil, err
+	}
+	var meta []string
+	var status string
+	var links []Relation
+	var reviews []Review
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		key, value := m.Content[i].Value, m.Content[i+1]
+		switch key {
+		case "id", "title":
+		case "status":
+			status = value.Value
+		case "links":
+			links = frontMatterRelations(m)
+		case "reviews":
+			reviews = frontMatterReviews(m)
+		default:
+			meta = append(meta, labelFor(key)+": "+frontMatterString(value))
+		}
+	}
+	statusSection := []string{"## Status", status, ""}
+	for _, l := range links {
+		statusSection = append(statusSection, l.markdown())
+	}
+	if len(links) > 0 {
+		statusSection = append(statusSection, "")
+	}
+	return editLines(in, func(lines []string) []string {
+		body := lines[bodyStart:]
+		// a Status section left with other text by toFrontMatter gets the status back, rather than a second one
+		hasStatus := false
+		for _, line := range body {
+			hasStatus = hasStatus || isStatusHeading(line)
+		}
+		var out []string
+		headingSeen, statusWritten := false, false
+		for _, line := range body {
+			if !statusWritten && (isStatusHeading(line) || (!hasStatus && strings.HasPrefix(line, "## "))) {
+				out = append(out, statusSection...)
+				statusWritten = true
+				if isStatusHeading(line) {
+					continue
+				}
+			}
+			out = append(out, line)
+			if strings.HasPrefix(line, "# ") && !headingSeen {
+				out = append(out, meta...)
+				headingSeen = true
+			}
+		}
+		if !headingSeen {
+			out = append(meta, out...)
+		}
+		if !statusWritten {
+			out = append(out, statusSection...)
+		}
+		if len(reviews) > 0 {
+			if len(out) > 0 && strings.TrimSpace(out[len(out)-1]) != "" {
+				out = append(out, "")
+			}
+			out = append(out, "## "+reviewSection)
+			for _, v := range reviews {
+				out = append(out, v.markdown())
+			}
+		}
+		return out
+	}), nil
+}
+
+// isStatusHeading reports whether line starts the Status section
+func isStatusHeading(line string) bool {
+	return strings.HasPrefix(line, "## ") && strings.EqualFold(strings.TrimSpace(line[3:]), "Status")
+}
+
+// Migrate stages the conversion of every record in repoDir to the given metadata style and returns how many records
+// were converted
+func (c *Changeset) Migrate(a *ADR, repoDir, style string) (int, error) {
+	if style != MetadataSections && style != MetadataFrontMatter {
+		return 0, fmt.Errorf("unknown metadata style '%s', expected %s or %s", style, MetadataSections, MetadataFrontMatter)
+	}
+	records, err := LoadRecords(c.FS, repoDir)
+	if err != nil {
+		return 0, err
+	}
+	converted := 0
+	for _, r := range records {
+		if r.frontMatter == (style == MetadataFrontMatter) {
+			continue
+		}
+		id := r.ID
+		err = c.rewrite(r.Path, func(in []byte) ([]byte, error) {
+			if style == MetadataFrontMatter {
+				return toFrontMatter(in, id, a.Fields)
+			}
+			return toSections(in)
+		})
+		if err != nil {
+			return converted, fmt.Errorf("unable to convert %s: %w", r.Path, err)
+		}
+		converted++
+	}
+	return converted, nil
+}
+
+// SetMetadataStyle makes style the metadata style of new records in the repository in use. Once a repository was
+// picked with Use the style is kept with that repository's own adr settings.
+func (c *Config) SetMetadataStyle(style string) {
+	c.ADR.Metadata = style
+	if c.selected != nil {
+		if c.Repository.ADR == nil {
+			c.Repository.ADR = &ADR{}
+		}
+		c.Repository.ADR.Metadata = style
+	}
+}
+
+// EditMetadataStyle returns the configuration file in with the metadata style set where SetMetadataStyle keeps it.
+// Only that value is edited, so the comments, key order and layout of the file are kept.
+func (c *Config) EditMetadataStyle(in []byte, style string) ([]byte, error) {
+	keys := []string{"adr", "metadata"}
+	if c.selected != nil {
+		if c.Repository == c.selected.repository {
+			keys = []string{"repository", "adr", "metadata"}
+		}
+		for i, r := range c.Repositories {
+			if r == c.Repository {
+				keys = []string{"repositories", strconv.Itoa(i), "adr", "metadata"}
+			}
+		}
+	}
+	return setYAMLValue(in, keys, style)
+}
@@ -0,0 +1,147 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path"
+	"strings"
+	"testing"
+)
+
+func Test_FrontMatterRecords(t *testing.T) {
+	startDir, workDir, err := setup()
+	handleHarnessErr(t, err)
+	defer cleanup(startDir, workDir)
+	c := NewDefaultConfig()
+	c.Metadata = MetadataFrontMatter
+	c.Fields = []*Field{{Name: "tags", Type: FieldList}}
+	repoDir := path.Join(workDir, DefaultRepositoryDir)
+	handleHarnessErr(t, c.New(repoDir, map[string]string{"Title": "first", "tags": "billing, data"}))
+	handleHarnessErr(t, c.New(repoDir, map[string]string{"Title": "second"}))
+	first, second := path.Join(repoDir, "001-first.md"), path.Join(repoDir, "002-second.md")
+	// begin test
+	b, err := os.ReadFile(first)
+	assert.NoError(t, err)
+	assert.Regexp(t, "^---\nid: 1\ntitle: first\nstatus: Proposed\n", string(b))
+	assert.NotContains(t, string(b), "## Status")
+	assert.NoError(t, Supersede(&LinkPair{SourceNum: 1, TargetNum: 2, SourceMsg: "moved on", RepoDir: repoDir}))
+	assert.NoError(t, UpdateStatus(second, "accepted"))
+	b, err = os.ReadFile(first)
+	assert.NoError(t, err)
+	r := ParseRecord(first, b)
+	assert.Equal(t, "Superseded", r.Status)
+	assert.Equal(t, []Relation{{Rel: "Superseded by", Target: "002-second.md", Message: "moved on"}}, r.Links)
+	assert.True(t, r.HasField("tags", "data"))
+	assert.False(t, HasErrors(c.Lint(r)))
+	b, err = os.ReadFile(second)
+	assert.NoError(t, err)
+	r = ParseRecord(second, b)
+	assert.Equal(t, "Accepted", r.Status)
+	assert.Equal(t, "Supersedes", r.Links[0].Rel)
+}
+
+func Test_MigrateRoundTrip(t *testing.T) {
+	startDir, workDir, err := setup()
+	handleHarnessErr(t, err)
+	defer cleanup(startDir, workDir)
+	c := NewDefaultConfig()
+	c.Fields = []*Field{{Name: "tags", Type: FieldList}}
+	repoDir := path.Join(workDir, DefaultRepositoryDir)
+	handleHarnessErr(t, c.New(repoDir, map[string]string{"Title": "first", "tags": "a, b"}))
+	handleHarnessErr(t, c.New(repoDir, map[string]string{"Title": "second"}))
+	handleHarnessErr(t, Link(&LinkPair{SourceNum: 2, TargetNum: 1, SourceMsg: "builds on", BackMsg: "built on by", RepoDir: repoDir}))
+	before, err := LoadRecords(nil, repoDir)
+	handleHarnessErr(t, err)
+	// begin test
+	cs := NewChangeset()
+	n, err := cs.Migrate(c.ADR, repoDir, MetadataFrontMatter)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.NoError(t, cs.Apply())
+	migrated, err := LoadRecords(nil, repoDir)
+	assert.NoError(t, err)
+	for i, r := range migrated {
+		assert.True(t, r.frontMatter)
+		assert.Equal(t, before[i].Status, r.Status)
+		assert.Equal(t, before[i].Links, r.Links)
+		assert.Equal(t, before[i].Metadata, r.Metadata)
+		assert.Nil(t, r.Section("Status"))
+	}
+	b, err := os.ReadFile(path.Join(repoDir, "001-first.md"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), "tags:\n  - a\n  - b\n", "list fields become YAML sequences")
+
+	cs = NewChangeset()
+	n, err = cs.Migrate(c.ADR, repoDir, MetadataSections)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.NoError(t, cs.Apply())
+	after, err := LoadRecords(nil, repoDir)
+	assert.NoError(t, err)
+	for i, r := range after {
+		assert.False(t, r.frontMatter)
+		assert.Equal(t, before[i].Status, r.Status)
+		assert.Equal(t, before[i].Links, r.Links)
+		assert.Equal(t, before[i].Metadata, r.Metadata)
+		assert.False(t, HasErrors(c.Lint(r)))
+	}
+	_, err = NewChangeset().Migrate(c.ADR, repoDir, "toml")
+	assert.Error(t, err)
+}
+
+func Test_EditMetadataStyle(t *testing.T) {
+	type test struct {
+		name     string
+		use      string
+		in       string
+		expected string
+	}
+	tests := []test{
+		{
+			name:     "Replaces the style in place",
+			in:       "# where records live\nrepository:\n  path: docs/adr # relative\nadr:\n  metadata: sections # the default\n  dateformat: '2006-01-02'\n",
+			expected: "# where records live\nrepository:\n  path: docs/adr # relative\nadr:\n  metadata: frontmatter # the default\n  dateformat: '2006-01-02'\n",
+		},
+		{
+			name:     "Adds the style next to the other settings",
+			in:       "repository:\n  path: docs/adr\nadr:\n  # ISO 8601\n  dateformat: '2006-01-02'\n",
+			expected: "repository:\n  path: docs/adr\nadr:\n  # ISO 8601\n  dateformat: '2006-01-02'\n  metadata: frontmatter\n",
+		},
+		{
+			name:     "Adds the settings",
+			in:       "# decisions\nrepository:\n    path: docs/adr\n",
+			expected: "# decisions\nrepository:\n    path: docs/adr\nadr:\n    metadata: frontmatter\n",
+		},
+		{
+			name:     "Keeps the style with the repository in use",
+			use:      "billing",
+			in:       "repositories:\n  - name: platform\n    path: platform\n  - name: billing # payments too\n    path: billing\n",
+			expected: "repositories:\n  - name: platform\n    path: platform\n  - name: billing # payments too\n    adr:\n      metadata: frontmatter\n    path: billing\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{}
+			handleHarnessErr(t, yaml.Unmarshal([]byte(tt.in), c))
+			handleHarnessErr(t, c.Use(tt.use))
+			out, err := c.EditMetadataStyle([]byte(tt.in), MetadataFrontMatter)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, string(out))
+		})
+	}
+}
+
+func Test_MigrateKeepsTheStatusText(t *testing.T) {
+	in := []byte("# 001. Use Postgres\n\n## Status\nAccepted\n\nAgreed at the March architecture forum.\n\n## Context\nWe need a database.\n")
+	// begin test
+	fm, err := toFrontMatter(in, "001", nil)
+	assert.NoError(t, err)
+	assert.Contains(t, string(fm), "## Status\n\nAgreed at the March architecture forum.\n", "other text stays in the Status section")
+	out, err := toSections(fm)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(out), "## Status"), string(out))
+	r := ParseRecord("001-use-postgres.md", out)
+	assert.Equal(t, "Accepted", r.Status)
+	assert.Contains(t, r.Section("Status").Content, "Agreed at the March architecture forum.")
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// defaultDateFormat is the layout of the Date of new records, ISO 8601
+const defaultDateFormat = "2006-01-02"
+
+// DateLayout returns the Go layout of the Date of records, adr.dateformat or ISO 8601 when that is not set
+func (a *ADR) DateLayout() string {
+	if a.DateFormat == "" {
+		return defaultDateFormat
+	}
+	return a.DateFormat
+}
+
+// location returns the configured time zone for the Date of new records, the local one by default
+func (a *ADR) location() (*time.Location, error) {
+	if a.TimeZone == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(a.TimeZone)
+	if err != nil {
+		return nil, fmt.Errorf("unknown adr.timezone '%s': %w", a.TimeZone, err)
+	}
+	return loc, nil
+}
+
+// RecordTime returns when a new record is dated: now, or the given date (in any layout ParseDate or the configured
+// adr.dateformat accepts) for backdated imports, in the configured time zone
+func (a *ADR) RecordTime(date string) (time.Time, error) {
+	loc, err := a.location()
+	if err != nil {
+		return time.Time{}, err
+	}
+	if date == "" {
+		return time.Now().In(loc), nil
+	}
+	t, ok := ParseDate(date, a.DateLayout())
+	if !ok {
+		return time.Time{}, fmt.Errorf("unable to read the date '%s', expected a date like %s", date, a.DateLayout())
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, loc), nil
+}
+
+// template returns an empty template named after the format with the function library for records dated at when:
+//
+//	date "Jan 2, 2006"   the record's date in the given Go layout
+//	now                  the record's date as a time.Time
+//	slugify "Some Title" the text as a slug following the configured slug rules
+//	upper, lower         change the case of text
+//	env "NAME"           the value of an environment variable
+//	gitUser              git's user.name, empty outside a repository
+//	pad 4 .Number        the number zero padded to the given width
+func (a *ADR) template(when time.Time) *template.Template {
+	return template.New(fmt.Sprintf("%s-adr", a.FormatName)).Funcs(template.FuncMap{
+		"date":    func(layout string) string { return when.Format(layout) },
+		"now":     func() time.Time { return when },
+		"slugify": a.Slugify,
+		"upper":   strings.ToUpper,
+		"lower":   strings.ToLower,
+		"env":     os.Getenv,
+		"gitUser": func() string {
+			name, _ := runGit("", "config", "user.name")
+			return name
+		},
+		"pad": pad,
+	})
+}
+
+// pad zero pads a number to width digits, text that is not a number is padded with leading zeros as is
+func pad(width int, s string) string {
+	if n, err := strconv.Atoi(strings.TrimSpace(s)); err == nil {
+		return fmt.Sprintf("%0*d", width, n)
+	}
+	if len(s) >= width {
+		return s
+	}
+	return strings.Repeat("0", width-len(s)) + s
+}
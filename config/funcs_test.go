@@ -0,0 +1,57 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path"
+	"testing"
+	"time"
+)
+
+func Test_NewDatesRecordsInISO8601(t *testing.T) {
+	startDir, workDir, err := setup()
+	handleHarnessErr(t, err)
+	defer cleanup(startDir, workDir)
+	c := NewDefaultConfig()
+	repoDir := path.Join(workDir, DefaultRepositoryDir)
+	// begin test
+	assert.NoError(t, c.New(repoDir, map[string]string{"Title": "today"}))
+	b, err := os.ReadFile(path.Join(repoDir, "001-today.md"))
+	assert.NoError(t, err)
+	assert.Equal(t, time.Now().Format("2006-01-02"), ParseRecord("", b).Date)
+	assert.NoError(t, c.New(repoDir, map[string]string{"Title": "imported", "Date": "2024-03-01"}))
+	b, err = os.ReadFile(path.Join(repoDir, "002-imported.md"))
+	assert.NoError(t, err)
+	assert.Equal(t, "2024-03-01", ParseRecord("", b).Date, "a given date backdates the record")
+	assert.Error(t, c.New(repoDir, map[string]string{"Title": "bad", "Date": "yesterday"}))
+}
+
+func Test_TemplateFunctions(t *testing.T) {
+	startDir, workDir, err := setup()
+	handleHarnessErr(t, err)
+	defer cleanup(startDir, workDir)
+	t.Setenv("ADR_TEAM", "payments")
+	c := NewDefaultConfig()
+	c.DateFormat = "02 Jan 2006"
+	c.TimeZone = "Asia/Tokyo"
+	c.TitleTemplate = `{{ pad 4 .Number }}-{{ .Title }}.md`
+	c.BodyTemplate = "# {{ .Number }}. {{ upper .Title }}\nDate: {{ .Date }}\nYear: {{ date \"2006\" }}\nSlug: {{ slugify \"Use Postgres!\" }}\nTeam: {{ env \"ADR_TEAM\" | lower }}\nZone: {{ now.Location }}\n\n## Status\nProposed\n"
+	repoDir := path.Join(workDir, DefaultRepositoryDir)
+	// begin test
+	assert.NoError(t, c.New(repoDir, map[string]string{"Title": "first", "Date": "2024-03-01"}))
+	b, err := os.ReadFile(path.Join(repoDir, "0001-first.md"))
+	assert.NoError(t, err)
+	assert.Equal(t, "# 001. FIRST\nDate: 01 Mar 2024\nYear: 2024\nSlug: use-postgres\nTeam: payments\nZone: Asia/Tokyo\n\n## Status\nProposed\n", string(b))
+	assert.Equal(t, "01 Mar 2024", ParseRecord("", b).Date)
+	_, ok := ParseDate("01 Mar 2024", c.DateFormat)
+	assert.True(t, ok, "the configured layout can be parsed back")
+
+	c.TimeZone = "Mars/Olympus_Mons"
+	assert.Error(t, c.New(repoDir, map[string]string{"Title": "second"}))
+}
+
+func Test_Pad(t *testing.T) {
+	assert.Equal(t, "0007", pad(4, "007"))
+	assert.Equal(t, "12345", pad(4, "12345"))
+	assert.Equal(t, "00ab", pad(4, "ab"))
+}
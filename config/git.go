@@ -0,0 +1,172 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+const (
+	defaultCommitMessage = "ADR {{ .Number }}: {{ .Status }}"
+	defaultBranchName    = "adr/{{ .Number }}-{{ .Title }}"
+)
+
+// Git contains the settings for recording ADR changes with the local git binary
+type Git struct {
+	Commit  bool   // commit the files touched by every operation without needing --commit
+	Message string // template for commit messages, see CommitValues for the available values
+	Branch  string // template for the branch created by propose
+}
+
+// GitOptions returns the configured git settings with defaults filled in for anything left unset
+func (c *Config) GitOptions() *Git {
+	g := &Git{Message: defaultCommitMessage, Branch: defaultBranchName}
+	if c.Git != nil {
+		g.Commit = c.Git.Commit
+		if c.Git.Message != "" {
+			g.Message = c.Git.Message
+		}
+		if c.Git.Branch != "" {
+			g.Branch = c.Git.Branch
+		}
+	}
+	return g
+}
+
+// CommitValues returns the template values describing the record at p as staged in cs: its Number, Title (the
+// filename slug), Status, and the Operation of cs
+func CommitValues(cs *Changeset, p string) map[string]string {
+	base := strings.TrimSuffix(filepath.Base(p), filepath.Ext(p))
+	n := idPattern.FindString(base)
+	v := map[string]string{
+		"Number":    n,
+		"Title":     strings.TrimPrefix(strings.TrimPrefix(base, n), "-"),
+		"Operation": cs.Operation,
+	}
+	if b, err := cs.Read(p); err == nil {
+		v["Status"] = statusOf(b)
+	}
+	return v
+}
+
+// render executes a one line template such as a commit message or branch name
+func render(name, text string, values map[string]string) (string, error) {
+	t, err := template.New(name).Parse(text)
+	if err != nil {
+		return "", err
+	}
+	b := bytes.NewBufferString("")
+	if err = t.Execute(b, values); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// runGit runs the local git binary in dir and returns its trimmed output
+func runGit(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out := &bytes.Buffer{}
+	cmd.Stdout = out
+	cmd.Stderr = out
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s: %v: %s", strings.Join(args, " "), err, strings.TrimSpace(out.String()))
+	}
+	return strings.TrimSpace(out.String()), nil
+}
+
+// Commit stages exactly the given paths, including removals, and commits only them with the message rendered
+// from the configured template
+func (c *Config) Commit(paths []string, values map[string]string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+	msg, err := render("commit-message", c.GitOptions().Message, values)
+	if err != nil {
+		return err
+	}
+	return c.CommitMessage(paths, msg)
+}
+
+// CommitMessage is Commit with the given message, for changes that are not about one record such as a migration
+func (c *Config) CommitMessage(paths []string, msg string) error {
+	paths, err := c.committable(paths)
+	if err != nil || len(paths) == 0 {
+		return err
+	}
+	args := append([]string{"add", "--all", "--"}, paths...)
+	if _, err = runGit(c.WorkingDirectory, args...); err != nil {
+		return err
+	}
+	args = append([]string{"commit", "--message", msg, "--"}, paths...)
+	_, err = runGit(c.WorkingDirectory, args...)
+	return err
+}
+
+// committable returns the paths git can commit: those that exist and the removed ones git tracks, leaving out files
+// that were written and removed again without ever being committed
+func (c *Config) committable(paths []string) ([]string, error) {
+	var kept []string
+	for _, p := range paths {
+		abs := p
+		if !filepath.IsAbs(abs) {
+			abs = filepath.Join(c.WorkingDirectory, p)
+		}
+		if _, err := os.Lstat(abs); err == nil {
+			kept = append(kept, p)
+			continue
+		}
+		tracked, err := runGit(c.WorkingDirectory, "ls-files", "--", p)
+		if err != nil {
+			return nil, err
+		}
+		if tracked != "" {
+			kept = append(kept, p)
+		}
+	}
+	return kept, nil
+}
+
+// CreateBranch creates and switches to the branch rendered from the configured template
+func (c *Config) CreateBranch(values map[string]string) (string, error) {
+	name, err := render("branch-name", c.GitOptions().Branch, values)
+	if err != nil {
+		return "", err
+	}
+	_, err = runGit(c.WorkingDirectory, "checkout", "-b", name)
+	return name, err
+}
+
+// InGitRepository reports whether the working directory is inside a git work tree
+func (c *Config) InGitRepository() bool {
+	out, err := runGit(c.WorkingDirectory, "rev-parse", "--is-inside-work-tree")
+	return err == nil && out == "true"
+}
+
+// ChangedSince returns the files changed since the current branch left ref, committed or not, including untracked
+// files, relative to the working directory
+func (c *Config) ChangedSince(ref string) ([]string, error) {
+	base, err := runGit(c.WorkingDirectory, "merge-base", ref, "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	changed, err := runGit(c.WorkingDirectory, "diff", "--name-only", "--relative", "--diff-filter=d", base)
+	if err != nil {
+		return nil, err
+	}
+	untracked, err := runGit(c.WorkingDirectory, "ls-files", "--others", "--exclude-standard")
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, p := range strings.Split(changed+"\n"+untracked, "\n") {
+		if p = strings.TrimSpace(p); p != "" {
+			paths = append(paths, filepath.FromSlash(p))
+		}
+	}
+	return paths, nil
+}
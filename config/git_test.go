@@ -0,0 +1,154 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os/exec"
+	"path"
+	"testing"
+)
+
+// setupGit is a test helper that initializes a git repository in the test env, skipping when git is unavailable
+func setupGit(t *testing.T, workDir string) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	for _, args := range [][]string{
+		{"init", "--quiet"},
+		{"config", "user.name", "Test Author"},
+		{"config", "user.email", "author@example.com"},
+		{"config", "commit.gpgsign", "false"},
+	} {
+		_, err := runGit(workDir, args...)
+		handleHarnessErr(t, err)
+	}
+}
+
+func Test_CommitOnlyTouchedFiles(t *testing.T) {
+	startDir, workDir, err := setup()
+	handleHarnessErr(t, err)
+	defer cleanup(startDir, workDir)
+	setupGit(t, workDir)
+	c := NewDefaultConfig()
+	repoDir := path.Join(workDir, DefaultRepositoryDir)
+	handleHarnessErr(t, writeAndClose(path.Join(workDir, "unrelated.txt"), "not part of the operation\n"))
+	cs := NewChangeset()
+	p, err := cs.New(c.ADR, repoDir, map[string]string{"Title": "use git"})
+	handleHarnessErr(t, err)
+	handleHarnessErr(t, cs.Apply())
+	// begin test
+	err = c.Commit(cs.Paths(), CommitValues(cs, p))
+	assert.NoError(t, err)
+	subject, err := runGit(workDir, "log", "-1", "--format=%s")
+	assert.NoError(t, err)
+	assert.Equal(t, "ADR 001: Proposed", subject)
+	files, err := runGit(workDir, "show", "--name-only", "--format=", "HEAD")
+	assert.NoError(t, err)
+	assert.Equal(t, path.Join(DefaultRepositoryDir, "001-use-git.md"), files, "only the new record should be committed")
+}
+
+func Test_CommitSkipsRemovedFilesNeverCommitted(t *testing.T) {
+	startDir, workDir, err := setup()
+	handleHarnessErr(t, err)
+	defer cleanup(startDir, workDir)
+	setupGit(t, workDir)
+	c := NewDefaultConfig()
+	repoDir := path.Join(workDir, DefaultRepositoryDir)
+	handleHarnessErr(t, c.New(repoDir, map[string]string{"Title": "use git"}))
+	cs := NewChangeset()
+	p, err := cs.Rename(c.ADR, repoDir, 1, "use mercurial", false)
+	handleHarnessErr(t, err)
+	handleHarnessErr(t, cs.Apply())
+	// begin test
+	assert.NoError(t, c.Commit(cs.Paths(), CommitValues(cs, p)), "the old name was never committed")
+	files, err := runGit(workDir, "show", "--name-only", "--format=", "HEAD")
+	assert.NoError(t, err)
+	assert.Equal(t, path.Join(DefaultRepositoryDir, "001-use-mercurial.md"), files)
+}
+
+func Test_CreateBranch(t *testing.T) {
+	startDir, workDir, err := setup()
+	handleHarnessErr(t, err)
+	defer cleanup(startDir, workDir)
+	setupGit(t, workDir)
+	c := NewDefaultConfig()
+	c.Git = &Git{Branch: "decisions/{{ .Number }}"}
+	// begin test
+	name, err := c.CreateBranch(map[string]string{"Number": "004", "Title": "x"})
+	assert.NoError(t, err)
+	assert.Equal(t, "decisions/004", name)
+	current, err := runGit(workDir, "symbolic-ref", "--short", "HEAD")
+	assert.NoError(t, err)
+	assert.Equal(t, "decisions/004", current)
+}
+
+func Test_HistoryFollowsStatusChangesAndRenames(t *testing.T) {
+	startDir, workDir, err := setup()
+	handleHarnessErr(t, err)
+	defer cleanup(startDir, workDir)
+	setupGit(t, workDir)
+	c := NewDefaultConfig()
+	repoDir := path.Join(workDir, DefaultRepositoryDir)
+	commitAll := func(msg string) {
+		_, err := runGit(workDir, "add", "--all")
+		handleHarnessErr(t, err)
+		_, err = runGit(workDir, "commit", "--quiet", "--message", msg)
+		handleHarnessErr(t, err)
+	}
+	handleHarnessErr(t, c.New(repoDir, map[string]string{"Title": "first"}))
+	commitAll("add")
+	created, err := runGit(workDir, "rev-parse", "HEAD")
+	handleHarnessErr(t, err)
+	handleHarnessErr(t, UpdateStatus(path.Join(repoDir, "001-first.md"), "Accepted"))
+	commitAll("accept")
+	accepted, err := runGit(workDir, "rev-parse", "HEAD")
+	handleHarnessErr(t, err)
+	p, err := c.Rename(repoDir, 1, "renamed", false)
+	handleHarnessErr(t, err)
+	commitAll("rename")
+	// begin test
+	h, err := c.History(p)
+	assert.NoError(t, err)
+	if assert.NotNil(t, h) {
+		assert.Equal(t, "Test Author", h.Author)
+		assert.Equal(t, created, h.CreatedCommit, "creation should be found through the rename")
+		if assert.Len(t, h.StatusChanges, 2) {
+			assert.Equal(t, "Proposed", h.StatusChanges[0].Status)
+			assert.Equal(t, "Accepted", h.StatusChanges[1].Status)
+			assert.Equal(t, accepted, h.StatusChanges[1].Commit)
+		}
+	}
+}
+
+func Test_ChangedSince(t *testing.T) {
+	startDir, workDir, err := setup()
+	handleHarnessErr(t, err)
+	defer cleanup(startDir, workDir)
+	setupGit(t, workDir)
+	c := NewDefaultConfig()
+	repoDir := DefaultRepositoryDir
+	commit := func(msg string) {
+		_, err := runGit(workDir, "add", "--all")
+		handleHarnessErr(t, err)
+		_, err = runGit(workDir, "commit", "--quiet", "--message", msg)
+		handleHarnessErr(t, err)
+	}
+	handleHarnessErr(t, c.New(repoDir, map[string]string{"Title": "first"}))
+	handleHarnessErr(t, c.New(repoDir, map[string]string{"Title": "second"}))
+	commit("base")
+	base, err := runGit(workDir, "rev-parse", "HEAD")
+	handleHarnessErr(t, err)
+	handleHarnessErr(t, c.New(repoDir, map[string]string{"Title": "committed"}))
+	commit("branch")
+	handleHarnessErr(t, UpdateStatus(path.Join(repoDir, "001-first.md"), "Accepted"))
+	handleHarnessErr(t, c.New(repoDir, map[string]string{"Title": "untracked"}))
+	// begin test
+	changed, err := c.ChangedSince(base)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{
+		path.Join(repoDir, "001-first.md"),
+		path.Join(repoDir, "003-committed.md"),
+		path.Join(repoDir, "004-untracked.md"),
+	}, changed)
+	_, err = c.ChangedSince("no-such-ref")
+	assert.Error(t, err)
+}
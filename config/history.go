@@ -0,0 +1,109 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// History is the timeline of a record derived from the git log of its file
+type History struct {
+	Author         string         `json:"author"`
+	AuthorEmail    string         `json:"authorEmail"`
+	CreatedCommit  string         `json:"createdCommit"`
+	Created        time.Time      `json:"created"`
+	ModifiedCommit string         `json:"modifiedCommit"`
+	Modified       time.Time      `json:"modified"`
+	StatusChanges  []StatusChange `json:"statusChanges,omitempty"`
+}
+
+// StatusChange is a commit in which the status of a record changed
+type StatusChange struct {
+	Status string    `json:"status"`
+	Commit string    `json:"commit"`
+	Author string    `json:"author"`
+	Time   time.Time `json:"time"`
+	// path is where the record was in the commit, relative to the top of the work tree
+	path string
+}
+
+// commitInfo is a single entry from git log
+type commitInfo struct {
+	hash   string
+	author string
+	email  string
+	time   time.Time
+	path   string
+}
+
+const (
+	logRecordSep = "\x1e"
+	logFieldSep  = "\x1f"
+)
+
+// History derives the author and timeline of the ADR at p from git. Renames are followed, so records keep their
+// history when retitled. Records that have never been committed return a nil History.
+func (c *Config) History(p string) (*History, error) {
+	commits, err := c.fileLog(p)
+	if err != nil || len(commits) == 0 {
+		return nil, err
+	}
+	first, last := commits[len(commits)-1], commits[0]
+	h := &History{
+		Author:         first.author,
+		AuthorEmail:    first.email,
+		CreatedCommit:  first.hash,
+		Created:        first.time,
+		ModifiedCommit: last.hash,
+		Modified:       last.time,
+	}
+	previous := ""
+	for i := len(commits) - 1; i >= 0; i-- {
+		ci := commits[i]
+		b, err := runGit(c.WorkingDirectory, "show", ci.hash+":"+ci.path)
+		if err != nil {
+			// the file was deleted in this commit, there is no status to read
+			continue
+		}
+		status := statusOf([]byte(b))
+		if status != "" && !strings.EqualFold(status, previous) {
+			h.StatusChanges = append(h.StatusChanges, StatusChange{Status: status, Commit: ci.hash, Author: ci.author, Time: ci.time, path: ci.path})
+			previous = status
+		}
+	}
+	return h, nil
+}
+
+// fileLog returns the commits that touched p, newest first, with the path the file had in each of them
+func (c *Config) fileLog(p string) ([]commitInfo, error) {
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return nil, err
+	}
+	out, err := runGit(c.WorkingDirectory, "log", "--follow", "--name-only",
+		"--format="+logRecordSep+strings.Join([]string{"%H", "%an", "%ae", "%aI"}, logFieldSep), "--", abs)
+	if err != nil {
+		return nil, err
+	}
+	var commits []commitInfo
+	for _, entry := range strings.Split(out, logRecordSep) {
+		lines := strings.Split(strings.TrimSpace(entry), "\n")
+		fields := strings.Split(lines[0], logFieldSep)
+		if len(fields) != 4 {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, fields[3])
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the date of commit %s: %w", fields[0], err)
+		}
+		ci := commitInfo{hash: fields[0], author: fields[1], email: fields[2], time: t}
+		for _, l := range lines[1:] {
+			if strings.TrimSpace(l) != "" {
+				ci.path = strings.TrimSpace(l)
+			}
+		}
+		commits = append(commits, ci)
+	}
+	return commits, nil
+}
@@ -0,0 +1,269 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Hooks are the git hooks 'adr hooks install' installs
+var Hooks = []string{"pre-commit", "commit-msg"}
+
+// hookMarker identifies the hooks written by adr so they are recognised when installing again or uninstalling
+const hookMarker = "# installed by adr hooks install"
+
+// chainedSuffix is added to the name of a hook that was in place before adr's, which adr's hook runs first
+const chainedSuffix = ".pre-adr"
+
+// hookScript returns the shell script of hook, which runs a hook it replaced before handing over to adr
+func hookScript(hook, command string) string {
+	return fmt.Sprintf(`#!/bin/sh
+%s
+chained="$(dirname "$0")/%s%s"
+if [ -x "$chained" ]; then
+	"$chained" "$@" || exit $?
+fi
+exec %s hooks run %s "$@"
+`, hookMarker, hook, chainedSuffix, command, hook)
+}
+
+// hookCommand returns how hooks call adr: by the path of this binary, as the adr on the PATH may be another tool
+// (e.g. adr-tools' adr, which has no 'hooks run'), and by name only when that path is unknown
+func hookCommand() string {
+	if p, err := os.Executable(); err == nil {
+		return "'" + strings.ReplaceAll(p, "'", `'\''`) + "'"
+	}
+	return "adr"
+}
+
+// HooksDir returns the directory git runs hooks from, following core.hooksPath
+func (c *Config) HooksDir() (string, error) {
+	dir, err := runGit(c.WorkingDirectory, "rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(c.WorkingDirectory, dir)
+	}
+	return dir, nil
+}
+
+// isAdrHook reports whether the hook at p was installed by adr
+func isAdrHook(p string) bool {
+	b, err := os.ReadFile(p)
+	return err == nil && bytes.Contains(b, []byte(hookMarker))
+}
+
+// InstallHooks installs the pre-commit and commit-msg hooks and returns their paths. A hook already in place is
+// kept and run first, installing again only refreshes adr's hooks.
+func (c *Config) InstallHooks() ([]string, error) {
+	dir, err := c.HooksDir()
+	if err != nil {
+		return nil, err
+	}
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	var installed []string
+	for _, hook := range Hooks {
+		p := filepath.Join(dir, hook)
+		if _, err := os.Stat(p); err == nil && !isAdrHook(p) {
+			chained := p + chainedSuffix
+			if _, err := os.Stat(chained); err == nil {
+				return installed, fmt.Errorf("unable to chain %s, %s already exists", p, chained)
+			}
+			if err = os.Rename(p, chained); err != nil {
+				return installed, err
+			}
+		}
+		if err = os.WriteFile(p, []byte(hookScript(hook, hookCommand())), 0755); err != nil {
+			return installed, err
+		}
+		installed = append(installed, p)
+	}
+	return installed, nil
+}
+
+// UninstallHooks removes adr's hooks, putting back the hooks they chained to, and returns the paths it removed
+func (c *Config) UninstallHooks() ([]string, error) {
+	dir, err := c.HooksDir()
+	if err != nil {
+		return nil, err
+	}
+	var removed []string
+	for _, hook := range Hooks {
+		p := filepath.Join(dir, hook)
+		if !isAdrHook(p) {
+			continue
+		}
+		if err = os.Remove(p); err != nil {
+			return removed, err
+		}
+		removed = append(removed, p)
+		if _, err := os.Stat(p + chainedSuffix); err == nil {
+			if err = os.Rename(p+chainedSuffix, p); err != nil {
+				return removed, err
+			}
+		}
+	}
+	return removed, nil
+}
+
+// settingsFor returns the adr settings of the repository the record at p belongs to, nil when p is not a record
+func (c *Config) settingsFor(p string) *ADR {
+	if filepath.Ext(p) != ".md" {
+		return nil
+	}
+	if _, num := splitID(filepath.Base(p)); num == "" {
+		return nil
+	}
+	if c.Repository != nil && within(c.Repository.Path, p) {
+		return c.ADR
+	}
+	for _, r := range c.Repositories {
+		if within(r.Path, p) {
+			if _, a, err := c.Lookup(r.Name); err == nil {
+				return a
+			}
+		}
+	}
+	return nil
+}
+
+// PreCommit lints the records staged for commit as they are staged, and reports edits to the sections that the
+// committed status of a record freezes, see adr.frozen
+func (c *Config) PreCommit() ([]Finding, error) {
+	staged, err := runGit(c.WorkingDirectory, "diff", "--cached", "--name-only", "--relative", "--no-renames", "--diff-filter=ACM")
+	if err != nil {
+		return nil, err
+	}
+	var findings []Finding
+	for _, p := range strings.Split(staged, "\n") {
+		p = filepath.FromSlash(strings.TrimSpace(p))
+		a := c.settingsFor(p)
+		if p == "" || a == nil {
+			continue
+		}
+		after, err := c.gitShow(":./" + filepath.ToSlash(p))
+		if err != nil {
+			return nil, err
+		}
+		findings = append(findings, a.Lint(ParseRecord(p, after))...)
+		if before, err := c.gitShow("HEAD:./" + filepath.ToSlash(p)); err == nil {
+			findings = append(findings, a.FrozenChanges(p, before, after)...)
+		}
+	}
+	return findings, nil
+}
+
+// gitShow returns the content of an object such as ':./docs/decisions/001-a.md', the staged version of a file
+func (c *Config) gitShow(object string) ([]byte, error) {
+	cmd := exec.Command("git", "show", object)
+	cmd.Dir = c.WorkingDirectory
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git show %s: %v", object, err)
+	}
+	return out, nil
+}
+
+// DecisionTrailer is the commit message trailer that refers to the decisions a commit implements
+const DecisionTrailer = "Decision"
+
+// trailerPattern matches a 'Decision: ADR-012' trailer, the value may list several records
+var trailerPattern = regexp.MustCompile(`(?i)^` + DecisionTrailer + `:\s*(.+)$`)
+
+// CheckCommitMessage checks that the Decision trailers of a commit message refer to existing records that were not
+// rejected. References are written like the record numbers of other commands, optionally prefixed with 'ADR-',
+// e.g. 'Decision: ADR-012' or 'Decision: billing:7, SEC-3'. Only the trailer block is checked, so a body line that
+// happens to start with 'Decision:' is left alone.
+func (c *Config) CheckCommitMessage(msg []byte) error {
+	var problems []string
+	for _, line := range trailerBlock(msg) {
+		m := trailerPattern.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			continue
+		}
+		for _, v := range SplitList(m[1]) {
+			if err := c.checkDecision(v); err != nil {
+				problems = append(problems, err.Error())
+			}
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid %s trailer: %s", DecisionTrailer, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// scissors is the line below which 'git commit --verbose' shows the diff, git drops it and everything after it
+const scissors = "# ------------------------ >8 ------------------------"
+
+// anyTrailerPattern matches a trailer line such as 'Signed-off-by: alice <alice@example.com>'
+var anyTrailerPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9-]*\s*:`)
+
+// trailerBlock returns the lines of the trailer block of a commit message, as git finds it: the last paragraph, when
+// it is not the subject and every line of it is a trailer or the continuation of one. Comments are left out.
+func trailerBlock(msg []byte) []string {
+	var paragraphs [][]string
+	var current []string
+	s := bufio.NewScanner(bytes.NewReader(msg))
+	for s.Scan() {
+		line := s.Text()
+		if line == scissors {
+			break
+		}
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.TrimSpace(line) == "" {
+			if len(current) > 0 {
+				paragraphs, current = append(paragraphs, current), nil
+			}
+			continue
+		}
+		current = append(current, line)
+	}
+	if len(current) > 0 {
+		paragraphs = append(paragraphs, current)
+	}
+	if len(paragraphs) < 2 {
+		return nil
+	}
+	block := paragraphs[len(paragraphs)-1]
+	for i, line := range block {
+		continuation := i > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t"))
+		if !continuation && !anyTrailerPattern.MatchString(line) {
+			return nil
+		}
+	}
+	return block
+}
+
+// checkDecision checks that a Decision trailer value refers to a record that was not rejected
+func (c *Config) checkDecision(v string) error {
+	ref, err := ParseRef(adrPrefix.ReplaceAllString(v, ""))
+	if err != nil {
+		return err
+	}
+	p, err := c.Locate(ref)
+	if err != nil {
+		return fmt.Errorf("%s: %w", v, err)
+	}
+	b, err := fileSystem(c.FS).ReadFile(p)
+	if err != nil {
+		return err
+	}
+	if status := statusOf(b); strings.EqualFold(status, "Rejected") {
+		return fmt.Errorf("%s was rejected", v)
+	}
+	return nil
+}
+
+// adrPrefix matches the 'ADR-' some write before record numbers, e.g. ADR-012
+var adrPrefix = regexp.MustCompile(`(?i)^adr-`)
@@ -0,0 +1,137 @@
+package config
+
+import (
+	"github.com/fleetingclarity/adr/storage"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path"
+	"strings"
+	"testing"
+)
+
+func Test_InstallHooksChainsExistingOnes(t *testing.T) {
+	startDir, workDir, err := setup()
+	handleHarnessErr(t, err)
+	defer cleanup(startDir, workDir)
+	setupGit(t, workDir)
+	c := NewDefaultConfig()
+	hooks := path.Join(workDir, ".git", "hooks")
+	handleHarnessErr(t, os.MkdirAll(hooks, 0755))
+	handleHarnessErr(t, os.WriteFile(path.Join(hooks, "pre-commit"), []byte("#!/bin/sh\nmake check\n"), 0755))
+	// begin test
+	installed, err := c.InstallHooks()
+	assert.NoError(t, err)
+	assert.Len(t, installed, 2)
+	b, err := os.ReadFile(path.Join(hooks, "pre-commit.pre-adr"))
+	assert.NoError(t, err)
+	assert.Equal(t, "#!/bin/sh\nmake check\n", string(b), "the existing hook is kept")
+	b, err = os.ReadFile(path.Join(hooks, "pre-commit"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), `"$chained" "$@" || exit $?`)
+	assert.Contains(t, string(b), `hooks run pre-commit "$@"`)
+	if exe, err := os.Executable(); assert.NoError(t, err) {
+		assert.Contains(t, string(b), exe, "hooks run this binary rather than whatever adr is on the PATH")
+	}
+	_, err = c.InstallHooks()
+	assert.NoError(t, err, "installing again refreshes adr's hooks")
+	b, err = os.ReadFile(path.Join(hooks, "pre-commit.pre-adr"))
+	assert.NoError(t, err)
+	assert.Equal(t, "#!/bin/sh\nmake check\n", string(b), "adr's hook is not chained to itself")
+
+	removed, err := c.UninstallHooks()
+	assert.NoError(t, err)
+	assert.Len(t, removed, 2)
+	b, err = os.ReadFile(path.Join(hooks, "pre-commit"))
+	assert.NoError(t, err)
+	assert.Equal(t, "#!/bin/sh\nmake check\n", string(b), "the chained hook is put back")
+	_, err = os.Stat(path.Join(hooks, "commit-msg"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func Test_PreCommitBlocksFrozenSections(t *testing.T) {
+	startDir, workDir, err := setup()
+	handleHarnessErr(t, err)
+	defer cleanup(startDir, workDir)
+	setupGit(t, workDir)
+	c := NewDefaultConfig()
+	c.WorkingDirectory = workDir
+	repoDir := DefaultRepositoryDir
+	git := func(args ...string) {
+		_, err := runGit(workDir, args...)
+		handleHarnessErr(t, err)
+	}
+	p := path.Join(repoDir, "001-use-postgres.md")
+	handleHarnessErr(t, c.New(repoDir, map[string]string{"Title": "Use Postgres"}))
+	handleHarnessErr(t, UpdateStatus(p, "Accepted"))
+	git("add", "--all")
+	git("commit", "--quiet", "--message", "accept")
+	edit := func(from, to string) {
+		b, err := os.ReadFile(p)
+		handleHarnessErr(t, err)
+		handleHarnessErr(t, os.WriteFile(p, []byte(strings.Replace(string(b), from, to, 1)), 0644))
+		git("add", "--all")
+	}
+	// begin test
+	edit("## Status\nAccepted\n", "## Status\nAccepted\n\n[Links to 002-x.md: amends](./002-x.md)\n")
+	findings, err := c.PreCommit()
+	assert.NoError(t, err)
+	assert.False(t, HasErrors(findings), "links and status may change")
+	edit("Describe the decision", "We changed our mind. Describe the decision")
+	findings, err = c.PreCommit()
+	assert.NoError(t, err)
+	assert.Contains(t, findings, Finding{Path: p, Line: 12, Column: 1, Rule: "frozen", Severity: SeverityError,
+		Message: "the Decision section of an Accepted record cannot change, supersede it instead"})
+}
+
+func Test_CheckCommitMessage(t *testing.T) {
+	startDir, workDir, err := setup()
+	handleHarnessErr(t, err)
+	defer cleanup(startDir, workDir)
+	c := NewDefaultConfig()
+	repoDir := DefaultRepositoryDir
+	handleHarnessErr(t, c.New(repoDir, map[string]string{"Title": "Use Postgres"}))
+	handleHarnessErr(t, c.New(repoDir, map[string]string{"Title": "Use Mongo"}))
+	handleHarnessErr(t, UpdateStatus(path.Join(repoDir, "002-use-mongo.md"), "Rejected"))
+	type test struct {
+		name    string
+		msg     string
+		problem string
+	}
+	tests := []test{
+		{name: "No trailer", msg: "Fix typo\n"},
+		{name: "Existing record", msg: "Add the schema\n\nDecision: ADR-001\n"},
+		{name: "Plain number", msg: "Add the schema\n\ndecision: 1\n"},
+		{name: "Comments are ignored", msg: "Add the schema\n\n# Decision: ADR-009\n"},
+		{name: "Missing record", msg: "Add the schema\n\nDecision: ADR-001, ADR-009\n", problem: "ADR-009: no file with number '009' found"},
+		{name: "Rejected record", msg: "Add mongo\n\nDecision: ADR-002\n", problem: "ADR-002 was rejected"},
+		{name: "Not a record", msg: "Add mongo\n\nDecision: mongo\n", problem: "expected a record number"},
+		{name: "Among other trailers", msg: "Add mongo\n\nSee the notes.\n\nDecision: ADR-002\nSigned-off-by: alice\n", problem: "ADR-002 was rejected"},
+		{name: "Body lines are not trailers", msg: "Add mongo\n\nDecision: ADR-009 is still open, so\nthis only adds the driver.\n"},
+		{name: "Only the last paragraph", msg: "Add mongo\n\nDecision: ADR-009\n\nSigned-off-by: alice\n"},
+		{name: "The subject is not a trailer", msg: "Decision: ADR-009\n"},
+		{name: "The diff is ignored", msg: "Add mongo\n\nSigned-off-by: alice\n" + scissors + "\nDecision: ADR-009\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := c.CheckCommitMessage([]byte(tt.msg))
+			if tt.problem == "" {
+				assert.NoError(t, err)
+			} else if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tt.problem)
+			}
+		})
+	}
+}
+
+func Test_CheckCommitMessageInMemory(t *testing.T) {
+	m := storage.NewMemory()
+	c := NewDefaultConfig()
+	c.FS = m
+	handleHarnessErr(t, m.MkdirAll(DefaultRepositoryDir, 0755))
+	handleHarnessErr(t, m.WriteFile(path.Join(DefaultRepositoryDir, "001-use-mongo.md"), []byte("# 1. Use Mongo\n\n## Status\n\nRejected\n"), 0644))
+	// begin test
+	err := c.CheckCommitMessage([]byte("Add mongo\n\nDecision: ADR-001\n"))
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "ADR-001 was rejected", "the record is read from the file system it was found in")
+	}
+}
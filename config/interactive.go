@@ -0,0 +1,155 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// endOfSection ends the multi-line answer for a section
+const endOfSection = "."
+
+// Interview prompts on out for everything a new record needs and reads the answers from in, one per line, so that it
+// can be scripted by piping stdin. It asks for the title unless values already has one, then for each declared field
+// and participant role not already in values, storing the answers in values. Finally it asks for the content of every
+// template section except Status; each answer ends with a line holding a single '.' (or the end of input) and an
+// empty answer keeps the template text. The section answers are returned keyed by section name.
+func (a *ADR) Interview(in io.Reader, out io.Writer, values map[string]string) (map[string]string, error) {
+	s := bufio.NewScanner(in)
+	eof := false
+	readLine := func() (string, bool) {
+		if eof || !s.Scan() {
+			eof = true
+			return "", false
+		}
+		return strings.TrimRight(s.Text(), "\r"), true
+	}
+	if values["Title"] == "" {
+		fmt.Fprint(out, "Title: ")
+		title, _ := readLine()
+		if strings.TrimSpace(title) == "" {
+			return nil, fmt.Errorf("a title is required")
+		}
+		values["Title"] = strings.TrimSpace(title)
+	}
+	for _, f := range a.Fields {
+		if values[f.Name] != "" {
+			continue
+		}
+		for {
+			fmt.Fprint(out, fieldPrompt(f, a.DateLayout()))
+			answer, ok := readLine()
+			answer = strings.TrimSpace(answer)
+			if answer == "" {
+				answer = f.Default
+			}
+			err := f.Validate(answer, a.DateLayout())
+			if err == nil {
+				values[f.Name] = answer
+				break
+			}
+			if !ok {
+				return nil, err
+			}
+			fmt.Fprintf(out, "%v\n", err)
+		}
+	}
+	for _, role := range ParticipantRoles {
+		if values[role] != "" {
+			continue
+		}
+		if role == RoleAuthor {
+			fmt.Fprintf(out, "%s (optional): ", role)
+		} else {
+			fmt.Fprintf(out, "%s (comma separated, optional): ", role)
+		}
+		answer, _ := readLine()
+		values[role] = strings.TrimSpace(answer)
+	}
+	sections := make(map[string]string)
+	for _, name := range a.templateSections() {
+		if strings.EqualFold(name, "Status") {
+			continue
+		}
+		fmt.Fprintf(out, "%s (end with a line containing only '%s', leave empty to keep the template text):\n", name, endOfSection)
+		var lines []string
+		for {
+			line, ok := readLine()
+			if !ok || line == endOfSection {
+				break
+			}
+			lines = append(lines, line)
+		}
+		if content := strings.TrimSpace(strings.Join(lines, "\n")); content != "" {
+			sections[name] = content
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return sections, nil
+}
+
+// fieldPrompt describes what a field accepts, e.g. 'cost-tier (one of low, high; default low): ', with dates in
+// the given layout
+func fieldPrompt(f *Field, layout string) string {
+	var hints []string
+	switch f.Type {
+	case FieldEnum:
+		hints = append(hints, "one of "+strings.Join(f.Values, ", "))
+	case FieldList:
+		if len(f.Values) > 0 {
+			hints = append(hints, "comma separated from "+strings.Join(f.Values, ", "))
+		} else {
+			hints = append(hints, "comma separated")
+		}
+	case FieldDate:
+		hints = append(hints, "a date like "+layout)
+	}
+	if f.Default != "" {
+		hints = append(hints, "default "+f.Default)
+	} else if !f.Required {
+		hints = append(hints, "optional")
+	}
+	if len(hints) == 0 {
+		return f.Name + ": "
+	}
+	return fmt.Sprintf("%s (%s): ", f.Name, strings.Join(hints, "; "))
+}
+
+// FillSections stages the replacement of the named sections' content in the record at p
+func (c *Changeset) FillSections(p string, sections map[string]string) error {
+	return c.rewrite(p, func(in []byte) ([]byte, error) {
+		for name, content := range sections {
+			in = setSectionContent(in, name, content)
+		}
+		return in, nil
+	})
+}
+
+// setSectionContent replaces everything between the named section's heading and the next section with content,
+// followed by a blank line when another section follows
+func setSectionContent(in []byte, name, content string) []byte {
+	return editLines(in, func(lines []string) []string {
+		var out []string
+		inSection := false
+		for _, line := range lines {
+			if strings.HasPrefix(line, "## ") {
+				if inSection {
+					out = append(out, "")
+				}
+				inSection = strings.EqualFold(strings.TrimSpace(strings.TrimPrefix(line, "## ")), name)
+				out = append(out, line)
+				if inSection {
+					out = append(out, strings.Split(content, "\n")...)
+				}
+				continue
+			}
+			if !inSection {
+				out = append(out, line)
+			}
+		}
+		return out
+	})
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path"
+	"strings"
+	"testing"
+)
+
+func Test_Interview(t *testing.T) {
+	startDir, workDir, err := setup()
+	handleHarnessErr(t, err)
+	defer cleanup(startDir, workDir)
+	c := NewDefaultConfig()
+	c.Fields = []*Field{{Name: "cost-tier", Type: FieldEnum, Values: []string{"low", "high"}, Default: "low"}}
+	repoDir := path.Join(workDir, DefaultRepositoryDir)
+	input := strings.Join([]string{
+		"Use postgres",
+		"mid", // rejected, asked again
+		"high",
+		"zed",
+		"alice, bob",
+		"",
+		"",
+		"We need a database.",
+		"",
+		"It must be relational.",
+		".",
+		"", // keep the Decision template text
+		".",
+		"Backups.",
+	}, "\n")
+	out := &bytes.Buffer{}
+	values := make(map[string]string)
+	// begin test
+	sections, err := c.Interview(strings.NewReader(input), out, values)
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), "cost-tier must be one of low, high, not 'mid'")
+	assert.Equal(t, "Use postgres", values["Title"])
+	assert.Equal(t, "high", values["cost-tier"])
+	assert.Equal(t, "alice, bob", values[RoleDeciders])
+	assert.Equal(t, map[string]string{"Context": "We need a database.\n\nIt must be relational.", "Consequences": "Backups."}, sections)
+
+	cs := NewChangeset()
+	p, err := cs.New(c.ADR, repoDir, values)
+	assert.NoError(t, err)
+	assert.NoError(t, cs.FillSections(p, sections))
+	assert.NoError(t, cs.Apply())
+	b, err := os.ReadFile(p)
+	assert.NoError(t, err)
+	r := ParseRecord(p, b)
+	assert.Equal(t, "We need a database.\n\nIt must be relational.", r.Section("Context").Content)
+	assert.Contains(t, r.Section("Decision").Content, "Describe the decision")
+	assert.Equal(t, "Backups.", r.Section("Consequences").Content)
+	assert.Equal(t, "Proposed", r.Status)
+	assert.Equal(t, "zed", r.Metadata["author"])
+	findings := c.Lint(r)
+	if assert.Len(t, findings, 1) {
+		assert.Equal(t, "template", findings[0].Rule, "the Decision section was left as the template had it")
+		assert.Equal(t, r.Section("Decision").Line+1, findings[0].Line)
+	}
+}
+
+func Test_InterviewNeedsATitle(t *testing.T) {
+	_, err := NewDefaultConfig().Interview(strings.NewReader("\n"), &bytes.Buffer{}, make(map[string]string))
+	assert.Error(t, err)
+}
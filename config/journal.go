@@ -0,0 +1,124 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/fleetingclarity/adr/storage"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultJournalName is the file, relative to the working directory, that records the last operation for undo
+const DefaultJournalName = ".adr-undo.json"
+
+// journal records enough about an applied Changeset to reverse it
+type journal struct {
+	Operation string         `json:"operation"`
+	Time      time.Time      `json:"time"`
+	Files     []journalEntry `json:"files"`
+}
+
+type journalEntry struct {
+	Path    string      `json:"path"`
+	Existed bool        `json:"existed"`
+	Mode    os.FileMode `json:"mode"`
+	Before  []byte      `json:"before,omitempty"`
+	Removed bool        `json:"removed"`
+	After   []byte      `json:"after,omitempty"`
+}
+
+// JournalPath returns where the undo journal is kept for the configured working directory. It lives inside the .git
+// directory when there is one so that it never shows up as an untracked file.
+func (c *Config) JournalPath() string {
+	gitDir := filepath.Join(c.WorkingDirectory, ".git")
+	if fi, err := fileSystem(c.FS).Stat(gitDir); err == nil && fi.IsDir() {
+		return filepath.Join(gitDir, DefaultJournalName)
+	}
+	return filepath.Join(c.WorkingDirectory, DefaultJournalName)
+}
+
+// writeJournal replaces the journal with the before and after state of the given paths
+func (c *Changeset) writeJournal(paths []string) error {
+	j := journal{Operation: c.Operation, Time: time.Now()}
+	fsys := fileSystem(c.FS)
+	for _, p := range paths {
+		s := c.files[p]
+		abs := p
+		if fsys == storage.OS {
+			var err error
+			if abs, err = filepath.Abs(p); err != nil {
+				return err
+			}
+		}
+		j.Files = append(j.Files, journalEntry{
+			Path:    abs,
+			Existed: s.existed,
+			Mode:    s.mode,
+			Before:  s.original,
+			Removed: s.removed,
+			After:   s.content,
+		})
+	}
+	b, err := json.Marshal(j)
+	if err != nil {
+		return err
+	}
+	tmp, err := writeTemp(fsys, c.Journal, b, 0600)
+	if err != nil {
+		return fmt.Errorf("unable to write the undo journal: %w", err)
+	}
+	return fsys.Rename(tmp, c.Journal)
+}
+
+// Undo stages the reversal of the operation recorded in the journal at journalPath and returns its description. It
+// refuses to continue if any of the recorded files were changed after the operation, unless force is true.
+func (c *Changeset) Undo(journalPath string, force bool) (string, error) {
+	b, err := fileSystem(c.FS).ReadFile(journalPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", errors.New("there is no operation to undo")
+	} else if err != nil {
+		return "", err
+	}
+	var j journal
+	if err = json.Unmarshal(b, &j); err != nil {
+		return "", fmt.Errorf("the undo journal %s is unreadable: %w", journalPath, err)
+	}
+	for _, e := range j.Files {
+		if !force && !unchangedSince(c, e) {
+			return "", fmt.Errorf("%s has changed since '%s', use --force to undo anyway", e.Path, j.Operation)
+		}
+	}
+	for _, e := range j.Files {
+		s, err := c.stage(e.Path)
+		if err != nil {
+			return "", err
+		}
+		s.mode = e.Mode
+		if e.Existed {
+			err = c.Write(e.Path, e.Before)
+		} else if c.Exists(e.Path) {
+			err = c.Remove(e.Path)
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	return j.Operation, nil
+}
+
+// RemoveJournal removes the journal at journalPath from the file system of the changeset, once its operation is undone
+func (c *Changeset) RemoveJournal(journalPath string) error {
+	return fileSystem(c.FS).Remove(journalPath)
+}
+
+// unchangedSince reports whether the file recorded in e still looks the way the journaled operation left it
+func unchangedSince(c *Changeset, e journalEntry) bool {
+	if e.Removed {
+		return !c.Exists(e.Path)
+	}
+	b, err := c.Read(e.Path)
+	return err == nil && bytes.Equal(b, e.After)
+}
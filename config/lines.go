@@ -0,0 +1,63 @@
+package config
+
+import (
+	"bytes"
+	"strings"
+)
+
+// utf8BOM is the byte order mark some Windows editors put at the start of UTF-8 files
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// lineFormat captures the byte level conventions of a file so that an edit can write them back unchanged
+type lineFormat struct {
+	bom          bool
+	eol          string
+	finalNewline bool
+}
+
+// splitLines breaks b into lines without their line endings and reports the conventions it used. The line ending of
+// the first line wins when a file mixes them.
+func splitLines(b []byte) ([]string, lineFormat) {
+	f := lineFormat{eol: "\n", finalNewline: true}
+	if bytes.HasPrefix(b, utf8BOM) {
+		f.bom = true
+		b = b[len(utf8BOM):]
+	}
+	if i := bytes.IndexByte(b, '\n'); i > 0 && b[i-1] == '\r' {
+		f.eol = "\r\n"
+	}
+	if len(b) == 0 {
+		return nil, f
+	}
+	s := string(b)
+	if strings.HasSuffix(s, "\n") {
+		s = strings.TrimSuffix(s, "\n")
+	} else {
+		f.finalNewline = false
+	}
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimSuffix(l, "\r")
+	}
+	return lines, f
+}
+
+// join reassembles lines using the conventions of f
+func (f lineFormat) join(lines []string) []byte {
+	b := &bytes.Buffer{}
+	if f.bom {
+		b.Write(utf8BOM)
+	}
+	b.WriteString(strings.Join(lines, f.eol))
+	if f.finalNewline && len(lines) > 0 {
+		b.WriteString(f.eol)
+	}
+	return b.Bytes()
+}
+
+// editLines runs edit over the lines of in and reassembles the result with the same BOM, line endings and final
+// newline that in had
+func editLines(in []byte, edit func(lines []string) []string) []byte {
+	lines, f := splitLines(in)
+	return f.join(edit(lines))
+}
@@ -0,0 +1,53 @@
+package config
+
+import (
+	"flag"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// Test_EditPreservesFileConventions runs a status update and a link through the edit engine for each input in
+// testdata/lineendings and compares the bytes written to the matching .golden file
+func Test_EditPreservesFileConventions(t *testing.T) {
+	inputs, err := filepath.Glob(filepath.Join("testdata", "lineendings", "*.md"))
+	handleHarnessErr(t, err)
+	assert.NotEmpty(t, inputs)
+	for _, in := range inputs {
+		name := strings.TrimSuffix(filepath.Base(in), ".md")
+		t.Run(name, func(t *testing.T) {
+			original, err := os.ReadFile(in)
+			handleHarnessErr(t, err)
+			p := filepath.Join(t.TempDir(), "001-first.md")
+			handleHarnessErr(t, os.WriteFile(p, original, 0644))
+			cs := NewChangeset()
+			assert.NoError(t, cs.UpdateStatus(p, "Accepted"))
+			assert.NoError(t, cs.addLink(p, Relation{Rel: "Links to", Target: "002-second.md", Message: "amends"}, "[Links to 002-second.md: amends](./002-second.md)"))
+			assert.NoError(t, cs.Apply())
+			actual, err := os.ReadFile(p)
+			assert.NoError(t, err)
+			golden := strings.TrimSuffix(in, ".md") + ".golden"
+			if *update {
+				handleHarnessErr(t, os.WriteFile(golden, actual, 0644))
+			}
+			expected, err := os.ReadFile(golden)
+			handleHarnessErr(t, err)
+			assert.Equal(t, string(expected), string(actual))
+		})
+	}
+}
+
+func Test_EditPreservesFileMode(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "001-first.md")
+	handleHarnessErr(t, os.WriteFile(p, []byte("# 001-first\n\n## Status\nProposed\n\n## Context\n"), 0640))
+	handleHarnessErr(t, os.Chmod(p, 0640))
+	// begin test
+	assert.NoError(t, UpdateStatus(p, "Accepted"))
+	fi, err := os.Stat(p)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0640), fi.Mode().Perm())
+}
@@ -0,0 +1,145 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Finding severities
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Finding is a problem lint found in a record. Line and Column start at 1.
+type Finding struct {
+	Path     string `json:"path"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s:%d:%d: %s: %s [%s]", f.Path, f.Line, f.Column, f.Severity, f.Message, f.Rule)
+}
+
+// Lint checks a record against the format: it must have a heading numbered like its file, a Status, every section
+// of the body template, valid values for the declared fields and readable revisit dates. Sections still holding the
+// template's placeholder text are warned about.
+func (a *ADR) Lint(r *Record) []Finding {
+	var findings []Finding
+	report := func(line int, rule, severity, msg string, args ...interface{}) {
+		if line < 1 {
+			line = 1
+		}
+		findings = append(findings, Finding{Path: r.Path, Line: line, Column: 1, Rule: rule, Severity: severity, Message: fmt.Sprintf(msg, args...)})
+	}
+	if r.titleLine == 0 {
+		report(1, "heading", SeverityError, "missing '# ' heading")
+	} else if id := idPattern.FindString(r.heading); id != "" && r.ID != "" {
+		prefix, n := splitID(id)
+		filePrefix, _ := splitID(r.ID)
+		if v, err := strconv.Atoi(n); err == nil && (v != r.Number || prefix != filePrefix) {
+			report(r.titleLine, "heading", SeverityError, "heading is numbered %s but the file is numbered %s", id, r.ID)
+		}
+	}
+	if r.frontMatter {
+		if r.Status == "" {
+			report(1, "status", SeverityError, "the front matter has no status")
+		}
+	} else if s := r.Section("Status"); s == nil {
+		report(r.titleLine, "status", SeverityError, "missing Status section")
+	} else if r.Status == "" {
+		report(s.Line, "status", SeverityError, "the Status section is empty")
+	}
+	for _, name := range a.templateSections() {
+		if r.frontMatter && strings.EqualFold(name, "Status") {
+			continue
+		}
+		if r.Section(name) == nil {
+			report(r.titleLine, "section", SeverityError, "missing %s section required by the %s template", name, a.FormatName)
+		}
+	}
+	templateText := a.templateText()
+	for _, s := range r.Sections {
+		boilerplate := templateText[strings.ToLower(s.Name)]
+		for i, line := range s.lines {
+			if boilerplate[strings.TrimSpace(line)] {
+				report(s.Line+1+i, "template", SeverityWarning, "the %s section still has the text from the template", s.Name)
+				break
+			}
+		}
+	}
+	for _, label := range []string{RevisitReviewBy, RevisitExpires} {
+		key := strings.ToLower(label)
+		if v, ok := r.Metadata[key]; ok && v != "" {
+			if _, ok := ParseDate(v, a.DateLayout()); !ok {
+				report(r.metadataLines[key], "revisit", SeverityError, "the %s date '%s' is not a date", label, v)
+			}
+		}
+	}
+	for _, f := range a.Fields {
+		key := strings.ToLower(f.Name)
+		line, ok := r.metadataLines[key]
+		if !ok {
+			line = r.titleLine
+		}
+		if err := f.Validate(r.Metadata[key], a.DateLayout()); err != nil {
+			report(line, "field", SeverityError, "%v", err)
+		}
+	}
+	return findings
+}
+
+// templateSections returns the names of the '## ' sections in the body template
+func (a *ADR) templateSections() []string {
+	var sections []string
+	for _, line := range strings.Split(a.BodyTemplate, "\n") {
+		if strings.HasPrefix(line, "## ") && !strings.Contains(line, "{{") {
+			sections = append(sections, strings.TrimSpace(strings.TrimPrefix(line, "## ")))
+		}
+	}
+	return sections
+}
+
+// templateText returns the non-blank lines of each section of the body template except Status, keyed by section
+// name in lower case. Lines with template actions are left out as they are different in every record.
+func (a *ADR) templateText() map[string]map[string]bool {
+	text := make(map[string]map[string]bool)
+	for _, s := range ParseRecord("", []byte(a.BodyTemplate)).Sections {
+		if strings.EqualFold(s.Name, "Status") {
+			continue
+		}
+		for _, line := range s.lines {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.Contains(line, "{{") {
+				continue
+			}
+			name := strings.ToLower(s.Name)
+			if text[name] == nil {
+				text[name] = make(map[string]bool)
+			}
+			text[name][line] = true
+		}
+	}
+	return text
+}
+
+// HasErrors reports whether any of the findings is an error rather than a warning
+func HasErrors(findings []Finding) bool {
+	return CountErrors(findings) > 0
+}
+
+// CountErrors returns the number of findings that are errors rather than warnings
+func CountErrors(findings []Finding) int {
+	n := 0
+	for _, f := range findings {
+		if f.Severity == SeverityError {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,210 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Metrics summarises an ADR repository
+type Metrics struct {
+	Total int `json:"total"`
+	// StatusCounts is keyed by lower case status
+	StatusCounts map[string]int `json:"statusCounts"`
+	// AcceptanceRatio is the share of resolved (i.e. no longer Proposed) records that were accepted, including those
+	// since superseded
+	AcceptanceRatio float64 `json:"acceptanceRatio"`
+	// RejectionRatio is the share of resolved records that were rejected
+	RejectionRatio float64 `json:"rejectionRatio"`
+	// LeadTime measures Proposed to Accepted, it is only available when records have git history
+	LeadTime *LeadTime `json:"leadTime,omitempty"`
+	// PerMonth counts records by the month (YYYY-MM) they were created
+	PerMonth map[string]int `json:"perMonth"`
+	// Superseded counts records that were replaced by a later decision, SupersedeChurn is their share of the total
+	Superseded     int     `json:"superseded"`
+	SupersedeChurn float64 `json:"supersedeChurn"`
+}
+
+// LeadTime is the distribution of time from Proposed to Accepted. It is written to JSON in hours, see MarshalJSON.
+type LeadTime struct {
+	Count  int
+	Sum    time.Duration
+	Median time.Duration
+	P90    time.Duration
+}
+
+// MarshalJSON writes the durations as hours, with the unit in their names, rather than as nanoseconds
+func (lt LeadTime) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Count       int     `json:"count"`
+		SumHours    float64 `json:"sumHours"`
+		MedianHours float64 `json:"medianHours"`
+		P90Hours    float64 `json:"p90Hours"`
+	}{lt.Count, lt.Sum.Hours(), lt.Median.Hours(), lt.P90.Hours()})
+}
+
+// ComputeMetrics calculates Metrics for the records of a repository with settings a, using their History when it has
+// been loaded
+func ComputeMetrics(a *ADR, records []*Record) *Metrics {
+	m := &Metrics{StatusCounts: make(map[string]int), PerMonth: make(map[string]int)}
+	var leadTimes []time.Duration
+	resolved, accepted, rejected := 0, 0, 0
+	for _, r := range records {
+		m.Total++
+		status := strings.ToLower(r.Status)
+		m.StatusCounts[status]++
+		switch status {
+		case "proposed", "":
+		case "accepted":
+			resolved++
+			accepted++
+		case "superseded":
+			resolved++
+			accepted++
+			m.Superseded++
+		case "rejected":
+			resolved++
+			rejected++
+		default:
+			resolved++
+		}
+		if created, ok := createdAt(a, r); ok {
+			m.PerMonth[created.Format("2006-01")]++
+		}
+		if d, ok := leadTime(r); ok {
+			leadTimes = append(leadTimes, d)
+		}
+	}
+	if resolved > 0 {
+		m.AcceptanceRatio = float64(accepted) / float64(resolved)
+		m.RejectionRatio = float64(rejected) / float64(resolved)
+	}
+	if m.Total > 0 {
+		m.SupersedeChurn = float64(m.Superseded) / float64(m.Total)
+	}
+	if len(leadTimes) > 0 {
+		sort.Slice(leadTimes, func(i, j int) bool { return leadTimes[i] < leadTimes[j] })
+		m.LeadTime = &LeadTime{Count: len(leadTimes), Median: median(leadTimes), P90: percentile(leadTimes, 0.9)}
+		for _, d := range leadTimes {
+			m.LeadTime.Sum += d
+		}
+	}
+	return m
+}
+
+// createdAt returns when a record was created, preferring its Date, in the date format of a, over the first commit
+func createdAt(a *ADR, r *Record) (time.Time, bool) {
+	if t, ok := ParseDate(r.Date, a.DateLayout()); ok {
+		return t, true
+	}
+	if r.History != nil {
+		return r.History.Created, true
+	}
+	return time.Time{}, false
+}
+
+// leadTime returns the time between a record first being Proposed and first being Accepted
+func leadTime(r *Record) (time.Duration, bool) {
+	if r.History == nil {
+		return 0, false
+	}
+	var proposed *StatusChange
+	for i, sc := range r.History.StatusChanges {
+		switch strings.ToLower(sc.Status) {
+		case "proposed":
+			if proposed == nil {
+				proposed = &r.History.StatusChanges[i]
+			}
+		case "accepted":
+			if proposed != nil {
+				return sc.Time.Sub(proposed.Time), true
+			}
+			return 0, false
+		}
+	}
+	return 0, false
+}
+
+// median of sorted durations
+func median(sorted []time.Duration) time.Duration {
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+// percentile of sorted durations using the nearest rank method
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// dateLayouts are the Date formats found in records, including the unpadded month name format of older versions
+var dateLayouts = []string{"2006-01-02", "2006-January-2", time.RFC3339}
+
+// ParseDate parses the Date of a record, trying the given layouts before the known ones
+func ParseDate(s string, layouts ...string) (time.Time, bool) {
+	for _, layout := range append(layouts, dateLayouts...) {
+		if t, err := time.Parse(layout, strings.TrimSpace(s)); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// WriteOpenMetrics writes m in the OpenMetrics text exposition format
+func (m *Metrics) WriteOpenMetrics(w io.Writer) error {
+	b := &strings.Builder{}
+	fmt.Fprintln(b, "# TYPE adr_records gauge")
+	fmt.Fprintln(b, "# HELP adr_records Number of records by status.")
+	for _, s := range SortedKeys(m.StatusCounts) {
+		fmt.Fprintf(b, "adr_records{status=\"%s\"} %d\n", escapeLabel(s), m.StatusCounts[s])
+	}
+	fmt.Fprintln(b, "# TYPE adr_acceptance_ratio gauge")
+	fmt.Fprintf(b, "adr_acceptance_ratio %g\n", m.AcceptanceRatio)
+	fmt.Fprintln(b, "# TYPE adr_rejection_ratio gauge")
+	fmt.Fprintf(b, "adr_rejection_ratio %g\n", m.RejectionRatio)
+	fmt.Fprintln(b, "# TYPE adr_superseded gauge")
+	fmt.Fprintf(b, "adr_superseded %d\n", m.Superseded)
+	fmt.Fprintln(b, "# TYPE adr_supersede_churn gauge")
+	fmt.Fprintf(b, "adr_supersede_churn %g\n", m.SupersedeChurn)
+	fmt.Fprintln(b, "# TYPE adr_decisions gauge")
+	fmt.Fprintln(b, "# HELP adr_decisions Number of records created per month.")
+	for _, month := range SortedKeys(m.PerMonth) {
+		fmt.Fprintf(b, "adr_decisions{month=\"%s\"} %d\n", month, m.PerMonth[month])
+	}
+	if lt := m.LeadTime; lt != nil {
+		fmt.Fprintln(b, "# TYPE adr_lead_time_seconds summary")
+		fmt.Fprintln(b, "# UNIT adr_lead_time_seconds seconds")
+		fmt.Fprintln(b, "# HELP adr_lead_time_seconds Time from Proposed to Accepted.")
+		fmt.Fprintf(b, "adr_lead_time_seconds{quantile=\"0.5\"} %g\n", lt.Median.Seconds())
+		fmt.Fprintf(b, "adr_lead_time_seconds{quantile=\"0.9\"} %g\n", lt.P90.Seconds())
+		fmt.Fprintf(b, "adr_lead_time_seconds_sum %g\n", lt.Sum.Seconds())
+		fmt.Fprintf(b, "adr_lead_time_seconds_count %d\n", lt.Count)
+	}
+	fmt.Fprintln(b, "# EOF")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// SortedKeys returns the keys of a count map, e.g. Metrics.StatusCounts, in order
+func SortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func escapeLabel(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
@@ -0,0 +1,66 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// proposedThenAccepted is a test helper for a record accepted the given number of days after it was proposed
+func proposedThenAccepted(date string, days int) *Record {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	return &Record{Status: "Accepted", Date: date, History: &History{
+		Created: start,
+		StatusChanges: []StatusChange{
+			{Status: "Proposed", Time: start},
+			{Status: "Accepted", Time: start.Add(time.Duration(days) * 24 * time.Hour)},
+		},
+	}}
+}
+
+func Test_ComputeMetrics(t *testing.T) {
+	records := []*Record{
+		proposedThenAccepted("2026-01-05", 1),
+		proposedThenAccepted("2026-01-20", 2),
+		proposedThenAccepted("2026-February-3", 10),
+		{Status: "Superseded", Date: "2026-02-10"},
+		{Status: "Rejected", Date: "2026-02-11"},
+		{Status: "Proposed", Date: "2026-03-01"},
+	}
+	// begin test
+	m := ComputeMetrics(NewDefaultConfig().ADR, records)
+	assert.Equal(t, 6, m.Total)
+	assert.Equal(t, map[string]int{"accepted": 3, "superseded": 1, "rejected": 1, "proposed": 1}, m.StatusCounts)
+	assert.InDelta(t, 0.8, m.AcceptanceRatio, 0.0001, "4 of 5 resolved records were accepted")
+	assert.InDelta(t, 0.2, m.RejectionRatio, 0.0001)
+	assert.Equal(t, map[string]int{"2026-01": 2, "2026-02": 3, "2026-03": 1}, m.PerMonth, "legacy dates should be understood")
+	assert.Equal(t, 1, m.Superseded)
+	if assert.NotNil(t, m.LeadTime) {
+		assert.Equal(t, 3, m.LeadTime.Count)
+		assert.Equal(t, 2*24*time.Hour, m.LeadTime.Median)
+		assert.Equal(t, 10*24*time.Hour, m.LeadTime.P90)
+	}
+	a := NewDefaultConfig().ADR
+	a.DateFormat = "02.01.2006"
+	m = ComputeMetrics(a, []*Record{{Status: "Accepted", Date: "05.01.2026"}})
+	assert.Equal(t, map[string]int{"2026-01": 1}, m.PerMonth, "dates are read in the configured format")
+}
+
+func Test_WriteOpenMetrics(t *testing.T) {
+	m := ComputeMetrics(NewDefaultConfig().ADR, []*Record{proposedThenAccepted("2026-01-05", 1)})
+	out := &bytes.Buffer{}
+	// begin test
+	assert.NoError(t, m.WriteOpenMetrics(out))
+	assert.Contains(t, out.String(), "adr_records{status=\"accepted\"} 1\n")
+	assert.Contains(t, out.String(), "adr_lead_time_seconds{quantile=\"0.5\"} 86400\n")
+	assert.Contains(t, out.String(), "adr_decisions{month=\"2026-01\"} 1\n")
+	assert.True(t, bytes.HasSuffix(out.Bytes(), []byte("# EOF\n")), "OpenMetrics output must end with # EOF")
+}
+
+func Test_LeadTimeJSON(t *testing.T) {
+	b, err := json.Marshal(&LeadTime{Count: 2, Sum: 60 * time.Hour, Median: 36 * time.Hour, P90: 90 * time.Minute})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"count": 2, "sumHours": 60, "medianHours": 36, "p90Hours": 1.5}`, string(b))
+}
@@ -0,0 +1,51 @@
+package config
+
+import (
+	"os"
+	"strings"
+)
+
+// Participant roles recorded with a decision, as in MADR. They are template variables (e.g. {{ .Deciders }}) and are
+// written as comma separated metadata lines (e.g. 'Deciders: alice, bob') when the template does not render them.
+const (
+	RoleAuthor    = "Author"
+	RoleDeciders  = "Deciders"
+	RoleConsulted = "Consulted"
+	RoleInformed  = "Informed"
+)
+
+// AuthorEnv overrides the author taken from git config
+const AuthorEnv = "ADR_AUTHOR"
+
+// ParticipantRoles lists the roles in the order they are written to a record
+var ParticipantRoles = []string{RoleAuthor, RoleDeciders, RoleConsulted, RoleInformed}
+
+// IsParticipantRole reports whether name is one of the participant roles, ignoring case
+func IsParticipantRole(name string) bool {
+	return containsFold(ParticipantRoles, name)
+}
+
+// isListKey reports whether the metadata key holds a list, either a declared list field or a role listing people
+func isListKey(fields []*Field, key string) bool {
+	if f := fieldNamed(fields, key); f != nil {
+		return f.Type == FieldList
+	}
+	return IsParticipantRole(key) && !strings.EqualFold(key, RoleAuthor)
+}
+
+// DefaultAuthor is the author of new records: $ADR_AUTHOR when set, otherwise git's user.name for dir, otherwise empty
+func DefaultAuthor(dir string) string {
+	if a := strings.TrimSpace(os.Getenv(AuthorEnv)); a != "" {
+		return a
+	}
+	name, err := runGit(dir, "config", "user.name")
+	if err != nil {
+		return ""
+	}
+	return name
+}
+
+// Participants returns the people recorded for a role, e.g. the deciders
+func (r *Record) Participants(role string) []string {
+	return SplitList(r.Metadata[strings.ToLower(role)])
+}
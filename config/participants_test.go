@@ -0,0 +1,38 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path"
+	"testing"
+)
+
+func Test_NewWritesParticipants(t *testing.T) {
+	startDir, workDir, err := setup()
+	handleHarnessErr(t, err)
+	defer cleanup(startDir, workDir)
+	c := NewDefaultConfig()
+	repoDir := path.Join(workDir, DefaultRepositoryDir)
+	// begin test
+	assert.NoError(t, c.New(repoDir, map[string]string{"Title": "first", RoleAuthor: "zed", RoleDeciders: "alice,bob", RoleConsulted: "team-db"}))
+	b, err := os.ReadFile(path.Join(repoDir, "001-first.md"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), "Author: zed\nDeciders: alice, bob\nConsulted: team-db\n")
+	assert.NotContains(t, string(b), "Informed:", "roles without people are left out")
+	r := ParseRecord("001-first.md", b)
+	assert.Equal(t, []string{"alice", "bob"}, r.Participants(RoleDeciders))
+	assert.True(t, r.HasField("deciders", "Bob"))
+
+	c.BodyTemplate = "# {{ .Number }}-{{ .Title }}\nDecided by {{ .Deciders }}{{ .Informed }}\n\n## Status\nProposed\n"
+	c.Metadata = MetadataFrontMatter
+	assert.NoError(t, c.New(repoDir, map[string]string{"Title": "second", RoleDeciders: "alice, bob"}))
+	b, err = os.ReadFile(path.Join(repoDir, "002-second.md"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), "Decided by alice, bob\n", "missing roles render empty")
+	assert.Contains(t, string(b), "deciders:\n  - alice\n  - bob\n", "participants are lists in front matter")
+}
+
+func Test_DefaultAuthorFromEnvironment(t *testing.T) {
+	t.Setenv(AuthorEnv, " zed ")
+	assert.Equal(t, "zed", DefaultAuthor(t.TempDir()))
+}
@@ -0,0 +1,215 @@
+package config
+
+import (
+	"github.com/fleetingclarity/adr/storage"
+	"gopkg.in/yaml.v3"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Record is an ADR parsed from its markdown
+type Record struct {
+	// ID is the number as written in the file name, with the category prefix if any, e.g. 007 or SEC-007
+	ID     string `json:"id"`
+	Number int    `json:"number"`
+	Path   string `json:"path"`
+	Title  string `json:"title"`
+	Date   string `json:"date,omitempty"`
+	Status string `json:"status"`
+	// Metadata holds the 'Key: value' lines under the heading, or the front matter, keyed by lower case key
+	Metadata map[string]string `json:"metadata,omitempty"`
+	Links    []Relation        `json:"links,omitempty"`
+	Reviews  []Review          `json:"reviews,omitempty"`
+	Sections []*Section        `json:"-"`
+	History  *History          `json:"history,omitempty"`
+	// heading is the text of the '# ' heading and titleLine is where it is (starting at 1)
+	heading   string
+	titleLine int
+	// metadataLines is where each metadata key is (starting at 1)
+	metadataLines map[string]int
+	// frontMatter is set when the record keeps its metadata in YAML front matter
+	frontMatter bool
+}
+
+// Section is a '## ' section of a record, Line is where its heading is (starting at 1)
+type Section struct {
+	Name    string
+	Line    int
+	Content string
+	// lines is the raw content, the first line is Line+1
+	lines []string
+}
+
+// Section returns the named section or nil when the record does not have it
+func (r *Record) Section(name string) *Section {
+	for _, s := range r.Sections {
+		if strings.EqualFold(s.Name, name) {
+			return s
+		}
+	}
+	return nil
+}
+
+// ParseRecord parses the contents of the ADR at p
+func ParseRecord(p string, b []byte) *Record {
+	r := &Record{Path: p, Metadata: make(map[string]string), metadataLines: make(map[string]int)}
+	prefix, num := splitID(filepath.Base(p))
+	if n, err := strconv.Atoi(num); err == nil {
+		r.Number = n
+		r.ID = num
+		if prefix != "" {
+			r.ID = prefix + "-" + num
+		}
+	}
+	lines, _ := splitLines(b)
+	fm, bodyStart, hasFM := splitFrontMatter(lines)
+	var current *Section
+	var content []string
+	closeSection := func() {
+		if current != nil {
+			current.lines = content
+			current.Content = strings.TrimSpace(strings.Join(content, "\n"))
+		}
+	}
+	for i, line := range lines {
+		switch {
+		case i < bodyStart:
+		case strings.HasPrefix(line, "## "):
+			closeSection()
+			current = &Section{Name: strings.TrimSpace(strings.TrimPrefix(line, "## ")), Line: i + 1}
+			r.Sections = append(r.Sections, current)
+			content = nil
+		case current != nil:
+			content = append(content, line)
+		case strings.HasPrefix(line, "# ") && r.Title == "":
+			r.heading = strings.TrimPrefix(line, "# ")
+			r.Title = headingTitle(r.heading)
+			r.titleLine = i + 1
+		default:
+			if k, v, ok := metadataLine(line); ok {
+				if _, seen := r.Metadata[k]; !seen {
+					r.Metadata[k] = v
+					r.metadataLines[k] = i + 1
+				}
+			}
+		}
+	}
+	closeSection()
+	if hasFM {
+		r.frontMatter = true
+		if m, err := parseFrontMatter(fm); err == nil {
+			r.applyFrontMatter(m)
+		}
+	} else if s := r.Section("Status"); s != nil {
+		for _, line := range s.lines {
+			if l, ok := parseRelation(line); ok {
+				r.Links = append(r.Links, l)
+			}
+		}
+	}
+	if s := r.Section(reviewSection); s != nil && !hasFM {
+		for _, line := range s.lines {
+			if v, ok := parseReview(line); ok {
+				r.Reviews = append(r.Reviews, v)
+			}
+		}
+	}
+	r.Date = r.Metadata["date"]
+	r.Status = statusOf(b)
+	return r
+}
+
+// applyFrontMatter fills the record from its front matter, which takes precedence over the body
+func (r *Record) applyFrontMatter(m *yaml.Node) {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		key, value := strings.ToLower(m.Content[i].Value), m.Content[i+1]
+		switch key {
+		case "id", "status":
+		case "title":
+			if value.Value != "" {
+				r.Title = value.Value
+			}
+		case "links":
+			r.Links = frontMatterRelations(m)
+		case "reviews":
+			r.Reviews = frontMatterReviews(m)
+		default:
+			r.Metadata[key] = frontMatterString(value)
+			// node lines count from the first line after the opening delimiter
+			r.metadataLines[key] = m.Content[i].Line + 1
+		}
+	}
+}
+
+// metadataKeys returns the metadata keys in the order they appear
+func (r *Record) metadataKeys() []string {
+	keys := make([]string, 0, len(r.Metadata))
+	for k := range r.Metadata {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return r.metadataLines[keys[i]] < r.metadataLines[keys[j]]
+	})
+	return keys
+}
+
+// metadataLineSet returns the lines holding metadata
+func (r *Record) metadataLineSet() map[int]bool {
+	set := make(map[int]bool)
+	for _, line := range r.metadataLines {
+		set[line] = true
+	}
+	return set
+}
+
+// headingTitle strips the record ID from a heading like '012-some-title', '12. Some title' or 'SEC-007. Some title'
+func headingTitle(h string) string {
+	id := idPattern.FindString(h)
+	if id == "" {
+		return strings.TrimSpace(h)
+	}
+	return strings.TrimSpace(strings.TrimLeft(strings.TrimPrefix(h, id), "-.: "))
+}
+
+// LoadRecords parses every ADR in repoDir of fsys (the disk when nil), ordered by category prefix and number.
+// Redirect stubs left by Rename are skipped.
+func LoadRecords(fsys storage.FS, repoDir string) ([]*Record, error) {
+	fsys = fileSystem(fsys)
+	var records []*Record
+	err := storage.WalkDir(fsys, repoDir, func(p string, info fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || filepath.Ext(p) != ".md" {
+			return nil
+		}
+		if _, num := splitID(filepath.Base(p)); num == "" {
+			return nil
+		}
+		b, err := fsys.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(string(b), redirectMarker) {
+			return nil
+		}
+		records = append(records, ParseRecord(p, b))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		pi, _ := splitID(records[i].ID)
+		pj, _ := splitID(records[j].ID)
+		if pi != pj {
+			// uncategorized records first, then by category prefix
+			return pi < pj
+		}
+		return records[i].Number < records[j].Number
+	})
+	return records, nil
+}
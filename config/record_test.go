@@ -0,0 +1,39 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"path"
+	"testing"
+)
+
+func Test_ParseRecord(t *testing.T) {
+	contents := "# 012-use-postgres\nDate: 2026-10-19\n\n## Status\nAccepted\n\n[Supersedes 003-x.md](./003-x.md)\n\n## Context\nWe need a database.\n\n## Decision\nUse Postgres.\n"
+	// begin test
+	r := ParseRecord("docs/decisions/012-use-postgres.md", []byte(contents))
+	assert.Equal(t, 12, r.Number)
+	assert.Equal(t, "use-postgres", r.Title)
+	assert.Equal(t, "2026-10-19", r.Date)
+	assert.Equal(t, "Accepted", r.Status)
+	assert.Len(t, r.Sections, 3)
+	assert.Equal(t, "Use Postgres.", r.Section("decision").Content)
+	assert.Equal(t, 9, r.Section("Context").Line)
+	assert.Nil(t, r.Section("Consequences"))
+}
+
+func Test_LoadRecordsSkipsRedirects(t *testing.T) {
+	startDir, workDir, err := setup()
+	handleHarnessErr(t, err)
+	defer cleanup(startDir, workDir)
+	c := NewDefaultConfig()
+	repoDir := path.Join(workDir, DefaultRepositoryDir)
+	handleHarnessErr(t, c.New(repoDir, map[string]string{"Title": "second"}))
+	handleHarnessErr(t, c.New(repoDir, map[string]string{"Title": "first"}))
+	_, err = c.Rename(repoDir, 1, "renamed", true)
+	handleHarnessErr(t, err)
+	// begin test
+	records, err := LoadRecords(nil, repoDir)
+	assert.NoError(t, err)
+	assert.Len(t, records, 2)
+	assert.Equal(t, "renamed", records[0].Title)
+	assert.Equal(t, 2, records[1].Number)
+}
@@ -0,0 +1,273 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/fleetingclarity/adr/storage"
+	"gopkg.in/yaml.v3"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// redirectMarker identifies a stub left behind by Rename so that Find can skip it
+const redirectMarker = "<!-- adr:redirect -->"
+
+// Rename will retitle the ADR with the given number. The filename is re-rendered through the TitleTemplate, the
+// heading is replaced with the one from the BodyTemplate, as is the title of front matter, and every other record in
+// repoDir that linked to the old filename is rewritten to point at the new one. When stub is true a redirect file is left at the old path.
+func (a *ADR) Rename(repoDir string, num int, title string, stub bool) (string, error) {
+	cs := NewChangeset()
+	p, err := cs.Rename(a, repoDir, num, title, stub)
+	if err != nil {
+		return "", err
+	}
+	return p, cs.Apply()
+}
+
+// Rename stages the retitling of the ADR with the given number, see ADR.Rename
+func (c *Changeset) Rename(a *ADR, repoDir string, num int, title string, stub bool) (string, error) {
+	oldPath, err := find(c.FS, a, repoDir, num)
+	if err != nil {
+		return "", err
+	}
+	return c.RenamePath(a, repoDir, oldPath, title, stub)
+}
+
+// RenamePath stages the retitling of the ADR at oldPath, which may be in any category of repoDir, see ADR.Rename.
+// Links to it are rewritten in the records of repoDir and of the other repository directories given.
+func (c *Changeset) RenamePath(a *ADR, repoDir, oldPath, title string, stub bool, others ...string) (string, error) {
+	oldBase := filepath.Base(oldPath)
+	values := map[string]string{
+		"Title":  strings.TrimSpace(title),
+		"Slug":   a.Slugify(title),
+		"Number": idPattern.FindString(oldBase),
+	}
+	tt, err := a.template(time.Now()).Parse(a.TitleTemplate)
+	if err != nil {
+		return "", err
+	}
+	nameBuffer := bytes.NewBufferString("")
+	err = tt.Execute(nameBuffer, fileNameValues(values))
+	if err != nil {
+		return "", err
+	}
+	newBase := nameBuffer.String()
+	newPath := filepath.Join(filepath.Dir(oldPath), newBase)
+	if newPath == oldPath {
+		return "", fmt.Errorf("%s already has the title '%s'", oldBase, title)
+	}
+	if c.Exists(newPath) {
+		return "", fmt.Errorf("unable to rename %s, %s already exists", oldBase, newBase)
+	}
+	heading, err := a.heading(values)
+	if err != nil {
+		return "", err
+	}
+	contents, err := c.Read(oldPath)
+	if err != nil {
+		return "", err
+	}
+	contents = replaceHeading(contents, heading)
+	if hasFrontMatter(contents) {
+		contents, err = editFrontMatter(contents, func(m *yaml.Node) error {
+			if frontMatterValue(m, "title") == nil {
+				return nil
+			}
+			return setFrontMatterValue(m, "title", values["Title"])
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+	err = c.writeAs(newPath, oldPath, contents)
+	if err != nil {
+		return "", err
+	}
+	err = c.Remove(oldPath)
+	if err != nil {
+		return "", err
+	}
+	err = c.rewriteLinks(append([]string{repoDir}, others...), oldPath, newBase)
+	if err != nil {
+		return "", err
+	}
+	if stub {
+		err = c.Write(oldPath, []byte(redirectStub(newBase)))
+		if err != nil {
+			return "", err
+		}
+	}
+	return newPath, nil
+}
+
+// heading renders the BodyTemplate with the given values and returns its first markdown heading
+func (a *ADR) heading(values map[string]string) (string, error) {
+	bt, err := a.template(time.Now()).Parse(a.BodyTemplate)
+	if err != nil {
+		return "", err
+	}
+	b := bytes.NewBufferString("")
+	err = bt.Execute(b, values)
+	if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(b.String(), "\n") {
+		if strings.HasPrefix(line, "# ") {
+			return line, nil
+		}
+	}
+	return "", fmt.Errorf("the %s body template does not contain a '# ' heading", a.FormatName)
+}
+
+// replaceHeading swaps the first markdown heading in contents for the given heading
+func replaceHeading(contents []byte, heading string) []byte {
+	return editLines(contents, func(lines []string) []string {
+		for i, line := range lines {
+			if strings.HasPrefix(line, "# ") {
+				lines[i] = heading
+				break
+			}
+		}
+		return lines
+	})
+}
+
+// linkPattern matches a markdown link, its text and its destination
+var linkPattern = regexp.MustCompile(`\[([^\]]*)\]\(([^)\s]+)\)`)
+
+// rewriteLinks stages the rewriting of the links to oldPath, renamed to newBase, in the other records of the given
+// directories. Only link destinations and front matter relation targets that resolve to oldPath are rewritten, along
+// with the target named in the text of such a link, so a record merely sharing the suffix of oldPath is left alone.
+func (c *Changeset) rewriteLinks(dirs []string, oldPath, newBase string) error {
+	newPath := filepath.Join(filepath.Dir(oldPath), newBase)
+	seen := make(map[string]bool)
+	for _, dir := range dirs {
+		err := storage.WalkDir(fileSystem(c.FS), dir, func(p string, info os.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() || filepath.Ext(p) != ".md" || samePath(p, newPath) || seen[absPath(p)] || !c.Exists(p) {
+				return nil
+			}
+			seen[absPath(p)] = true
+			contents, err := c.Read(p)
+			if err != nil {
+				return err
+			}
+			out, err := rewriteRecordLinks(p, contents, oldPath, newBase)
+			if err != nil || bytes.Equal(out, contents) {
+				return err
+			}
+			return c.Write(p, out)
+		})
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rewriteRecordLinks returns the contents of the record at p with its links to oldPath pointing at newBase
+func rewriteRecordLinks(p string, contents []byte, oldPath, newBase string) ([]byte, error) {
+	retarget := func(target string) (string, bool) {
+		dest, anchor, _ := strings.Cut(target, "#")
+		if dest == "" || strings.Contains(dest, "://") || path.Base(dest) != filepath.Base(oldPath) ||
+			!samePath(filepath.Join(filepath.Dir(p), filepath.FromSlash(dest)), oldPath) {
+			return target, false
+		}
+		renamed := strings.TrimSuffix(dest, path.Base(dest)) + newBase
+		if anchor != "" {
+			renamed += "#" + anchor
+		}
+		return renamed, true
+	}
+	out := linkPattern.ReplaceAllFunc(contents, func(link []byte) []byte {
+		m := linkPattern.FindSubmatch(link)
+		target, ok := retarget(string(m[2]))
+		if !ok {
+			return link
+		}
+		old := strings.TrimPrefix(string(m[2]), "./")
+		text := replaceName(string(m[1]), old, strings.TrimPrefix(target, "./"))
+		return []byte(fmt.Sprintf("[%s](%s)", text, target))
+	})
+	if !hasFrontMatter(out) {
+		return out, nil
+	}
+	lines, _ := splitLines(out)
+	fm, _, _ := splitFrontMatter(lines)
+	m, err := parseFrontMatter(fm)
+	if err != nil {
+		return nil, err
+	}
+	links, changed := frontMatterRelations(m), false
+	for i, l := range links {
+		if target, ok := retarget(l.Target); ok {
+			links[i].Target, changed = target, true
+		}
+	}
+	if !changed {
+		return out, nil
+	}
+	return editFrontMatter(out, func(m *yaml.Node) error {
+		return setFrontMatterValue(m, "links", links)
+	})
+}
+
+// replaceName replaces the whole file names old in text with new, leaving names that merely end with old alone
+func replaceName(text, old, new string) string {
+	var b strings.Builder
+	for {
+		i := strings.Index(text, old)
+		if i < 0 {
+			b.WriteString(text)
+			return b.String()
+		}
+		b.WriteString(text[:i])
+		if i > 0 && isNameChar(text[i-1]) {
+			b.WriteString(old)
+		} else {
+			b.WriteString(new)
+		}
+		text = text[i+len(old):]
+	}
+}
+
+// isNameChar reports whether c may be part of a path written in a link
+func isNameChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.IndexByte("/-_.", c) >= 0
+}
+
+// samePath reports whether a and b are the same file, whether they are absolute or relative
+func samePath(a, b string) bool {
+	return absPath(a) == absPath(b)
+}
+
+// absPath returns p made absolute, or cleaned when that fails
+func absPath(p string) string {
+	if abs, err := filepath.Abs(p); err == nil {
+		return abs
+	}
+	return filepath.Clean(p)
+}
+
+func redirectStub(newBase string) string {
+	return fmt.Sprintf("%s\nThis record has been renamed to [%s](./%s)\n", redirectMarker, newBase, newBase)
+}
+
+// isRedirect reports whether the file at p of fsys is a stub left behind by Rename
+func isRedirect(fsys storage.FS, p string) bool {
+	contents, err := fileSystem(fsys).ReadFile(p)
+	if err != nil {
+		return false
+	}
+	return strings.HasPrefix(string(contents), redirectMarker)
+}
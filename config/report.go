@@ -0,0 +1,169 @@
+package config
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// lintRules describes each lint rule for reports that list them, e.g. SARIF
+var lintRules = map[string]string{
+	"heading":  "The record has a '# ' heading numbered like its file",
+	"status":   "The record has a status",
+	"section":  "The record has every section of the body template",
+	"template": "Sections no longer hold the placeholder text of the body template",
+	"field":    "Declared fields have valid values",
+	"frozen":   "Sections frozen by the status of a record under adr.frozen do not change",
+	"revisit":  "Review-By and Expires are dates",
+}
+
+// sarifLevels maps finding severities to SARIF result levels
+var sarifLevels = map[string]string{SeverityError: "error", SeverityWarning: "warning"}
+
+// WriteSARIF writes findings as a SARIF 2.1.0 log, the format code scanning tools such as GitHub's read
+func WriteSARIF(w io.Writer, findings []Finding) error {
+	type message struct {
+		Text string `json:"text"`
+	}
+	type rule struct {
+		ID               string  `json:"id"`
+		ShortDescription message `json:"shortDescription"`
+	}
+	type location struct {
+		PhysicalLocation struct {
+			ArtifactLocation struct {
+				URI string `json:"uri"`
+			} `json:"artifactLocation"`
+			Region struct {
+				StartLine   int `json:"startLine"`
+				StartColumn int `json:"startColumn"`
+			} `json:"region"`
+		} `json:"physicalLocation"`
+	}
+	type result struct {
+		RuleID    string     `json:"ruleId"`
+		Level     string     `json:"level"`
+		Message   message    `json:"message"`
+		Locations []location `json:"locations"`
+	}
+	var rules []rule
+	var ids []string
+	for id := range lintRules {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		rules = append(rules, rule{ID: id, ShortDescription: message{Text: lintRules[id]}})
+	}
+	results := make([]result, 0, len(findings))
+	for _, f := range findings {
+		var l location
+		l.PhysicalLocation.ArtifactLocation.URI = filepath.ToSlash(f.Path)
+		l.PhysicalLocation.Region.StartLine = f.Line
+		l.PhysicalLocation.Region.StartColumn = f.Column
+		results = append(results, result{RuleID: f.Rule, Level: sarifLevels[f.Severity], Message: message{Text: f.Message}, Locations: []location{l}})
+	}
+	log := map[string]interface{}{
+		"$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+		"version": "2.1.0",
+		"runs": []interface{}{map[string]interface{}{
+			"tool": map[string]interface{}{"driver": map[string]interface{}{
+				"name":           "adr",
+				"informationUri": "https://github.com/fleetingclarity/adr",
+				"rules":          rules,
+			}},
+			"results": results,
+		}},
+	}
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+	return e.Encode(log)
+}
+
+// WriteJUnit writes findings as a JUnit XML report with a test case per checked record, failing those with errors.
+// Warnings are kept in the output of their test case.
+func WriteJUnit(w io.Writer, paths []string, findings []Finding) error {
+	type failure struct {
+		Message string `xml:"message,attr"`
+		Type    string `xml:"type,attr"`
+		Text    string `xml:",chardata"`
+	}
+	type testCase struct {
+		Name      string   `xml:"name,attr"`
+		ClassName string   `xml:"classname,attr"`
+		Failure   *failure `xml:"failure,omitempty"`
+		SystemOut string   `xml:"system-out,omitempty"`
+	}
+	type testSuite struct {
+		XMLName  xml.Name   `xml:"testsuite"`
+		Name     string     `xml:"name,attr"`
+		Tests    int        `xml:"tests,attr"`
+		Failures int        `xml:"failures,attr"`
+		Cases    []testCase `xml:"testcase"`
+	}
+	byPath := make(map[string][]Finding)
+	for _, f := range findings {
+		if _, ok := byPath[f.Path]; !ok && !containsPath(paths, f.Path) {
+			paths = append(paths, f.Path)
+		}
+		byPath[f.Path] = append(byPath[f.Path], f)
+	}
+	suite := testSuite{Name: "adr lint"}
+	for _, p := range paths {
+		tc := testCase{Name: filepath.ToSlash(p), ClassName: "adr.lint"}
+		var errs, warnings []string
+		for _, f := range byPath[p] {
+			if f.Severity == SeverityError {
+				errs = append(errs, f.String())
+			} else {
+				warnings = append(warnings, f.String())
+			}
+		}
+		if len(errs) > 0 {
+			tc.Failure = &failure{Message: fmt.Sprintf("%d error(s)", len(errs)), Type: "lint", Text: strings.Join(errs, "\n")}
+			suite.Failures++
+		}
+		tc.SystemOut = strings.Join(warnings, "\n")
+		suite.Cases = append(suite.Cases, tc)
+	}
+	suite.Tests = len(suite.Cases)
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	e := xml.NewEncoder(w)
+	e.Indent("", "  ")
+	if err := e.Encode(suite); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// containsPath reports whether paths holds p
+func containsPath(paths []string, p string) bool {
+	for _, q := range paths {
+		if q == p {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteGitHub writes findings as GitHub Actions workflow commands, which annotate the lines of the files in a pull
+// request
+func WriteGitHub(w io.Writer, findings []Finding) error {
+	data := strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+	property := strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+	for _, f := range findings {
+		_, err := fmt.Fprintf(w, "::%s file=%s,line=%d,col=%d,title=%s::%s\n", f.Severity, property.Replace(filepath.ToSlash(f.Path)),
+			f.Line, f.Column, property.Replace("adr lint "+f.Rule), data.Replace(f.Message))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
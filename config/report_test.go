@@ -0,0 +1,81 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var reportFindings = []Finding{
+	{Path: "docs/decisions/001-a.md", Line: 1, Column: 1, Rule: "heading", Severity: SeverityError, Message: "heading is numbered 002, but 100%\nwrong"},
+	{Path: "docs/decisions/001-a.md", Line: 9, Column: 1, Rule: "template", Severity: SeverityWarning, Message: "the Context section still has the text from the template"},
+}
+
+func Test_WriteGitHub(t *testing.T) {
+	b := &bytes.Buffer{}
+	assert.NoError(t, WriteGitHub(b, reportFindings))
+	assert.Equal(t, "::error file=docs/decisions/001-a.md,line=1,col=1,title=adr lint heading::heading is numbered 002, but 100%25%0Awrong\n"+
+		"::warning file=docs/decisions/001-a.md,line=9,col=1,title=adr lint template::the Context section still has the text from the template\n", b.String())
+}
+
+func Test_WriteSARIF(t *testing.T) {
+	b := &bytes.Buffer{}
+	assert.NoError(t, WriteSARIF(b, reportFindings))
+	var log struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Results []struct {
+				RuleID    string `json:"ruleId"`
+				Level     string `json:"level"`
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							URI string `json:"uri"`
+						} `json:"artifactLocation"`
+						Region struct {
+							StartLine int `json:"startLine"`
+						} `json:"region"`
+					} `json:"physicalLocation"`
+				} `json:"locations"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	assert.NoError(t, json.Unmarshal(b.Bytes(), &log))
+	assert.Equal(t, "2.1.0", log.Version)
+	results := log.Runs[0].Results
+	assert.Len(t, results, 2)
+	assert.Equal(t, "warning", results[1].Level)
+	assert.Equal(t, "template", results[1].RuleID)
+	assert.Equal(t, "docs/decisions/001-a.md", results[1].Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	assert.Equal(t, 9, results[1].Locations[0].PhysicalLocation.Region.StartLine)
+}
+
+func Test_WriteJUnit(t *testing.T) {
+	b := &bytes.Buffer{}
+	assert.NoError(t, WriteJUnit(b, []string{"docs/decisions/001-a.md", "docs/decisions/002-b.md"}, reportFindings))
+	var suite struct {
+		Tests    int `xml:"tests,attr"`
+		Failures int `xml:"failures,attr"`
+		Cases    []struct {
+			Name    string `xml:"name,attr"`
+			Failure *struct {
+				Text string `xml:",chardata"`
+			} `xml:"failure"`
+			SystemOut string `xml:"system-out"`
+		} `xml:"testcase"`
+	}
+	assert.NoError(t, xml.Unmarshal(b.Bytes(), &suite))
+	assert.Equal(t, 2, suite.Tests)
+	assert.Equal(t, 1, suite.Failures)
+	assert.Contains(t, suite.Cases[0].Failure.Text, "[heading]")
+	assert.Contains(t, suite.Cases[0].SystemOut, "[template]", "warnings do not fail the test case")
+	assert.Nil(t, suite.Cases[1].Failure)
+}
+
+func Test_CountErrors(t *testing.T) {
+	assert.Equal(t, 1, CountErrors(reportFindings), "warnings are not counted")
+	assert.True(t, HasErrors(reportFindings))
+	assert.False(t, HasErrors(reportFindings[1:]))
+}
@@ -1,6 +1,205 @@
 package config
 
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
 // Repository contains the metadata for an ADR repository (e.g. configurations, path, etc)
 type Repository struct {
+	// Name identifies one of several repositories, e.g. in --repo billing or a link to billing:12
+	Name string `yaml:"name,omitempty"`
 	Path string `yaml:"path"`
+	// ADR overrides the adr settings, such as the templates, for this repository
+	ADR *ADR `yaml:"adr,omitempty"`
+}
+
+// Lookup returns the named repository and the adr settings that apply to it. The empty name is the default
+// repository: the one under 'repository' in .adr.yaml, or else the first under 'repositories'.
+func (c *Config) Lookup(name string) (*Repository, *ADR, error) {
+	base, baseRepository := c.ADR, c.Repository
+	if c.selected != nil {
+		base, baseRepository = c.selected.base, c.selected.repository
+	}
+	if base == nil {
+		base = NewDefaultConfig().ADR
+	}
+	if name == "" {
+		if baseRepository == nil && len(c.Repositories) > 0 {
+			baseRepository = c.Repositories[0]
+		}
+		if baseRepository == nil {
+			return nil, nil, fmt.Errorf("no repository is configured, run adr init")
+		}
+		return baseRepository, base.merge(baseRepository.ADR), nil
+	}
+	var names []string
+	for _, r := range c.Repositories {
+		if r.Name == name {
+			return r, base.merge(r.ADR), nil
+		}
+		names = append(names, r.Name)
+	}
+	if baseRepository != nil && baseRepository.Name == name {
+		return baseRepository, base.merge(baseRepository.ADR), nil
+	}
+	return nil, nil, fmt.Errorf("unknown repository '%s', expected one of: %s", name, strings.Join(names, ", "))
+}
+
+// Validate reports settings that cannot work, such as a category name that references could not name
+func (c *Config) Validate() error {
+	adrs := []*ADR{c.ADR}
+	if c.selected != nil {
+		adrs = append(adrs, c.selected.base)
+	}
+	if c.Repository != nil {
+		adrs = append(adrs, c.Repository.ADR)
+	}
+	for _, r := range c.Repositories {
+		adrs = append(adrs, r.ADR)
+	}
+	for _, a := range adrs {
+		if err := a.validateCategories(); err != nil {
+			return fmt.Errorf("invalid adr.categories: %w", err)
+		}
+	}
+	return nil
+}
+
+// Use makes the named repository, and its adr settings, the one that Repository and ADR refer to. Write still
+// writes the configuration as it was read.
+func (c *Config) Use(name string) error {
+	if name == "" && len(c.Repositories) == 0 {
+		return nil
+	}
+	r, a, err := c.Lookup(name)
+	if err != nil {
+		return err
+	}
+	if c.selected == nil {
+		c.selected = &selection{base: c.ADR, repository: c.Repository}
+	}
+	c.Repository, c.ADR = r, a
+	return nil
+}
+
+// selection remembers the configuration as read once Use has replaced Repository and ADR
+type selection struct {
+	base       *ADR
+	repository *Repository
+}
+
+// merge returns a copy of a with the settings given in override replacing its own
+func (a *ADR) merge(override *ADR) *ADR {
+	m := *a
+	if override == nil {
+		return &m
+	}
+	for _, s := range []struct{ dst, src *string }{
+		{&m.FormatName, &override.FormatName},
+		{&m.TitleTemplate, &override.TitleTemplate},
+		{&m.BodyTemplate, &override.BodyTemplate},
+		{&m.Metadata, &override.Metadata},
+		{&m.DateFormat, &override.DateFormat},
+		{&m.TimeZone, &override.TimeZone},
+	} {
+		if *s.src != "" {
+			*s.dst = *s.src
+		}
+	}
+	if override.Fields != nil {
+		m.Fields = override.Fields
+	}
+	if override.Slug != nil {
+		m.Slug = override.Slug
+	}
+	if override.Categories != nil {
+		m.Categories = override.Categories
+	}
+	if override.Review != nil {
+		m.Review = override.Review
+	}
+	if override.Revisit != nil {
+		m.Revisit = override.Revisit
+	}
+	if override.Frozen != nil {
+		m.Frozen = override.Frozen
+	}
+	return &m
+}
+
+// Ref is a record in one of the repositories, written '12' for the current repository and no category, or with a
+// repository name and a category name or prefix, e.g. 'billing:12', 'SEC-7', 'security/7' or 'billing:SEC-7'
+type Ref struct {
+	Repository string
+	Category   string
+	Number     int
+}
+
+var refPattern = regexp.MustCompile(`^(?:([^:]+):)?(?:([A-Za-z][A-Za-z0-9_-]*)[-/])?(\d+)$`)
+
+// ParseRef parses a record reference such as '12', 'billing:12' or 'SEC-12'
+func ParseRef(s string) (Ref, error) {
+	m := refPattern.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return Ref{}, fmt.Errorf("expected a record number like 12, SEC-12 or repository:12, not '%s'", s)
+	}
+	n, err := strconv.Atoi(m[3])
+	if err != nil {
+		return Ref{}, err
+	}
+	return Ref{Repository: m[1], Category: m[2], Number: n}, nil
+}
+
+// Resolve returns the directory of the repository a reference is in, the current repository when it names none
+func (c *Config) Resolve(ref Ref) (string, error) {
+	if ref.Repository == "" {
+		return c.Repository.Path, nil
+	}
+	r, _, err := c.Lookup(ref.Repository)
+	if err != nil {
+		return "", err
+	}
+	return r.Path, nil
+}
+
+// Dirs returns the directory of every repository of the project, the current one first
+func (c *Config) Dirs() []string {
+	var dirs []string
+	seen := make(map[string]bool)
+	add := func(r *Repository) {
+		if r != nil && !seen[r.Path] {
+			seen[r.Path] = true
+			dirs = append(dirs, r.Path)
+		}
+	}
+	add(c.Repository)
+	if c.selected != nil {
+		add(c.selected.repository)
+	}
+	for _, r := range c.Repositories {
+		add(r)
+	}
+	return dirs
+}
+
+// relativeTarget returns the link from the record at from to the record at to, just the file name when both are in
+// the same directory
+func relativeTarget(from, to string) string {
+	rel, err := filepath.Rel(filepath.Dir(from), to)
+	if err != nil {
+		return filepath.Base(to)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// href is the markdown link destination for a relation target
+func href(target string) string {
+	if strings.HasPrefix(target, "../") {
+		return target
+	}
+	return "./" + target
 }
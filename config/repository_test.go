@@ -0,0 +1,116 @@
+package config
+
+import (
+	"bytes"
+	"github.com/fleetingclarity/adr/storage"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path"
+	"testing"
+)
+
+func multiRepoConfig() *Config {
+	c := NewDefaultConfig()
+	c.Repository = nil
+	c.Repositories = []*Repository{
+		{Name: "platform", Path: "docs/platform"},
+		{Name: "billing", Path: "services/billing/adr", ADR: &ADR{TitleTemplate: "{{ .Number }}-billing-{{ .Slug }}.md"}},
+	}
+	return c
+}
+
+func Test_UseRepository(t *testing.T) {
+	c := multiRepoConfig()
+	before := &bytes.Buffer{}
+	handleHarnessErr(t, c.Write(before))
+	// begin test
+	assert.NoError(t, c.Use(""))
+	assert.Equal(t, "docs/platform", c.Repository.Path, "the first repository is the default")
+	assert.Equal(t, defaultTitleTemplate, c.TitleTemplate)
+	assert.NoError(t, c.Use("billing"))
+	assert.Equal(t, "services/billing/adr", c.Repository.Path)
+	assert.Equal(t, "{{ .Number }}-billing-{{ .Slug }}.md", c.TitleTemplate, "repository settings override the adr settings")
+	assert.Equal(t, defaultBodyTemplate, c.BodyTemplate, "settings not overridden are kept")
+	assert.Error(t, c.Use("nope"))
+	after := &bytes.Buffer{}
+	assert.NoError(t, c.Write(after))
+	assert.Equal(t, before.String(), after.String(), "the configuration is written as read")
+
+	c.SetMetadataStyle(MetadataFrontMatter)
+	assert.Equal(t, MetadataFrontMatter, c.Metadata)
+	assert.Equal(t, MetadataFrontMatter, c.Repositories[1].ADR.Metadata, "the style is kept with the repository")
+	assert.Empty(t, c.selected.base.Metadata)
+}
+
+func Test_ParseRef(t *testing.T) {
+	ref, err := ParseRef("12")
+	assert.NoError(t, err)
+	assert.Equal(t, Ref{Number: 12}, ref)
+	ref, err = ParseRef("billing:7")
+	assert.NoError(t, err)
+	assert.Equal(t, Ref{Repository: "billing", Number: 7}, ref)
+	_, err = ParseRef("billing:x")
+	assert.Error(t, err)
+	ref, err = ParseRef("billing:data-platform/7")
+	assert.NoError(t, err)
+	assert.Equal(t, Ref{Repository: "billing", Category: "data-platform", Number: 7}, ref)
+}
+
+func Test_LoadConfigCategories(t *testing.T) {
+	m := storage.NewMemory()
+	handleHarnessErr(t, m.WriteFile(".adr.yaml", []byte(`repositories:
+  - name: platform
+    path: platform
+  - name: billing
+    path: billing
+    adr:
+      categories:
+        - name: data-platform
+          directory: data
+`), 0644))
+	// begin test
+	c, err := LoadConfig(m, ".")
+	assert.NoError(t, err)
+	_, a, err := c.Lookup("billing")
+	assert.NoError(t, err)
+	cat, err := a.Category("data-platform")
+	assert.NoError(t, err, "the categories of a repository are kept")
+	assert.Equal(t, "data", cat.Directory)
+
+	handleHarnessErr(t, m.WriteFile(".adr.yaml", []byte("adr:\n  categories:\n    - name: data platform\n"), 0644))
+	_, err = LoadConfig(m, ".")
+	assert.Error(t, err, "references could not name the category")
+	handleHarnessErr(t, m.WriteFile(".adr.yaml", []byte("adr:\n  categories:\n    - name: security\n      prefix: SEC-OPS\n"), 0644))
+	_, err = LoadConfig(m, ".")
+	assert.Error(t, err, "record IDs could not start with the prefix")
+	handleHarnessErr(t, m.WriteFile(".adr.yaml", []byte("adr:\n  categories:\n    - name: security\n"), 0644))
+	_, err = LoadConfig(m, ".")
+	assert.Error(t, err, "every uncategorized record would belong to the category")
+}
+
+func Test_LinkAcrossRepositories(t *testing.T) {
+	startDir, workDir, err := setup()
+	handleHarnessErr(t, err)
+	defer cleanup(startDir, workDir)
+	c := multiRepoConfig()
+	platform, billing := path.Join(workDir, "docs/platform"), path.Join(workDir, "services/billing/adr")
+	handleHarnessErr(t, os.MkdirAll(platform, 0755))
+	handleHarnessErr(t, os.MkdirAll(billing, 0755))
+	handleHarnessErr(t, c.Use("platform"))
+	handleHarnessErr(t, c.New(platform, map[string]string{"Title": "Use Postgres"}))
+	handleHarnessErr(t, c.Use("billing"))
+	handleHarnessErr(t, c.New(billing, map[string]string{"Title": "Billing database"}))
+	dir, err := c.Resolve(Ref{Repository: "platform", Number: 1})
+	handleHarnessErr(t, err)
+	// begin test
+	assert.Equal(t, "docs/platform", dir)
+	assert.NoError(t, Supersede(&LinkPair{SourceNum: 1, TargetNum: 1, SourceMsg: "moved", RepoDir: platform, TargetRepoDir: billing}))
+	b, err := os.ReadFile(path.Join(platform, "001-use-postgres.md"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), "](../../services/billing/adr/001-billing-billing-database.md)")
+	r := ParseRecord("001-use-postgres.md", b)
+	assert.Equal(t, []Relation{{Rel: "Superseded by", Target: "../../services/billing/adr/001-billing-billing-database.md", Message: "moved"}}, r.Links)
+	b, err = os.ReadFile(path.Join(billing, "001-billing-billing-database.md"))
+	assert.NoError(t, err)
+	assert.Equal(t, "../../../docs/platform/001-use-postgres.md", ParseRecord("", b).Links[0].Target)
+}
@@ -0,0 +1,204 @@
+package config
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"regexp"
+	"strings"
+)
+
+// Review states
+const (
+	ReviewRequested = "requested"
+	ReviewApproved  = "approved"
+)
+
+// reviewSection is the section records in sections style keep their reviews in
+const reviewSection = "Review"
+
+// ReviewPolicy is what a record needs before it can be accepted with 'adr accept'
+type ReviewPolicy struct {
+	// Approvals is the number of reviewers who must approve, 1 when not set
+	Approvals int `yaml:"approvals,omitempty"`
+	// AllRequested also requires every requested reviewer to approve
+	AllRequested bool `yaml:"allrequested,omitempty"`
+}
+
+// Review is a review requested from or given by one reviewer. Reviews are only ever appended so a record keeps its
+// whole review history.
+type Review struct {
+	Reviewer string `yaml:"reviewer" json:"reviewer"`
+	State    string `yaml:"state" json:"state"`
+	Date     string `yaml:"date,omitempty" json:"date,omitempty"`
+	Comment  string `yaml:"comment,omitempty" json:"comment,omitempty"`
+}
+
+// reviewLabels are how each state is written in the Review section
+var reviewLabels = map[string]string{ReviewRequested: "Requested from", ReviewApproved: "Approved by"}
+
+// reviewPattern matches the lines of the Review section, e.g. '- Approved by alice on 2024-01-02: looks good', see
+// parseReview for how the reviewer, date and comment are told apart
+var reviewPattern = regexp.MustCompile(`^- (Requested from|Approved by) (.+)$`)
+
+// markdown renders the review as a line of the Review section
+func (v Review) markdown() string {
+	line := fmt.Sprintf("- %s %s", reviewLabels[v.State], v.Reviewer)
+	if v.Date != "" {
+		line += " on " + v.Date
+	}
+	if v.Comment != "" {
+		line += ": " + v.Comment
+	}
+	return line
+}
+
+// parseReview reads a line of the Review section. The comment follows the first colon and space, so dates with a
+// time such as '2024-01-02 15:04' are read whole. The date follows the last ' on ' and has a digit, as every date
+// layout writes one, so reviewers such as 'Team on call' are read whole too.
+func parseReview(line string) (Review, bool) {
+	m := reviewPattern.FindStringSubmatch(strings.TrimSpace(line))
+	if m == nil {
+		return Review{}, false
+	}
+	reviewer, comment, _ := strings.Cut(m[2], ": ")
+	v := Review{Reviewer: reviewer, Comment: comment}
+	if i := strings.LastIndex(reviewer, " on "); i > 0 && strings.ContainsAny(reviewer[i+len(" on "):], "0123456789") {
+		v.Reviewer, v.Date = reviewer[:i], reviewer[i+len(" on "):]
+	}
+	for state, label := range reviewLabels {
+		if label == m[1] {
+			v.State = state
+		}
+	}
+	return v, true
+}
+
+// withoutReviews returns the Review section without its reviews, or nothing if that leaves it empty
+func (s *Section) withoutReviews() []string {
+	var kept []string
+	for _, line := range s.lines {
+		if _, ok := parseReview(line); !ok {
+			kept = append(kept, line)
+		}
+	}
+	if strings.TrimSpace(strings.Join(kept, "")) == "" {
+		return nil
+	}
+	return append([]string{"## " + s.Name}, kept...)
+}
+
+// frontMatterReviews decodes the reviews of a front matter mapping
+func frontMatterReviews(m *yaml.Node) []Review {
+	var reviews []Review
+	if n := frontMatterValue(m, "reviews"); n != nil {
+		_ = n.Decode(&reviews)
+	}
+	return reviews
+}
+
+// approvals returns the reviewers that approved, each once, in the order they first approved
+func approvals(reviews []Review) []string {
+	var approved []string
+	for _, v := range reviews {
+		if v.State == ReviewApproved && !containsFold(approved, v.Reviewer) {
+			approved = append(approved, v.Reviewer)
+		}
+	}
+	return approved
+}
+
+// Check returns an error describing the approvals still missing from reviews
+func (p *ReviewPolicy) Check(reviews []Review) error {
+	needed, all := 1, false
+	if p != nil {
+		if p.Approvals > 0 {
+			needed = p.Approvals
+		}
+		all = p.AllRequested
+	}
+	approved := approvals(reviews)
+	if len(approved) < needed {
+		return fmt.Errorf("%d of %d required approvals recorded", len(approved), needed)
+	}
+	if all {
+		var waiting []string
+		for _, v := range reviews {
+			if v.State == ReviewRequested && !containsFold(approved, v.Reviewer) && !containsFold(waiting, v.Reviewer) {
+				waiting = append(waiting, v.Reviewer)
+			}
+		}
+		if len(waiting) > 0 {
+			return fmt.Errorf("still waiting for the approval of %s", strings.Join(waiting, ", "))
+		}
+	}
+	return nil
+}
+
+// Review stages the reviews of the record at p, dated today in the repository's date format
+func (c *Changeset) Review(a *ADR, p string, reviews ...Review) error {
+	when, err := a.RecordTime("")
+	if err != nil {
+		return err
+	}
+	date := when.Format(a.DateLayout())
+	for i := range reviews {
+		if strings.TrimSpace(reviews[i].Reviewer) == "" {
+			return fmt.Errorf("a review needs a reviewer")
+		}
+		if reviews[i].Date == "" {
+			reviews[i].Date = date
+		}
+	}
+	return c.rewrite(p, func(in []byte) ([]byte, error) {
+		if hasFrontMatter(in) {
+			return editFrontMatter(in, func(m *yaml.Node) error {
+				return setFrontMatterValue(m, "reviews", append(frontMatterReviews(m), reviews...))
+			})
+		}
+		var lines []string
+		for _, v := range reviews {
+			lines = append(lines, v.markdown())
+		}
+		if ParseRecord("", in).Section(reviewSection) != nil {
+			return appendToSection(in, reviewSection, strings.Join(lines, "\n"))
+		}
+		return editLines(in, func(body []string) []string {
+			if len(body) > 0 && strings.TrimSpace(body[len(body)-1]) != "" {
+				body = append(body, "")
+			}
+			return append(append(body, "## "+reviewSection), lines...)
+		}), nil
+	})
+}
+
+// SetStatus stages the replacement of the status of the record at p like UpdateStatus, except that when a review
+// policy is configured (adr.review) the record is only accepted once it has the approvals the policy asks for
+func (c *Changeset) SetStatus(a *ADR, p, to string) error {
+	if a.Review == nil || !strings.EqualFold(strings.TrimSpace(to), "Accepted") {
+		return c.UpdateStatus(p, to)
+	}
+	b, err := c.Read(p)
+	if err != nil {
+		return err
+	}
+	if err = a.Review.Check(ParseRecord(p, b).Reviews); err != nil {
+		return fmt.Errorf("%s cannot be accepted yet: %w", p, err)
+	}
+	return c.UpdateStatus(p, to)
+}
+
+// Accept stages the acceptance of the record at p, refusing until it has the approvals the review policy asks for
+func (c *Changeset) Accept(a *ADR, p string) error {
+	b, err := c.Read(p)
+	if err != nil {
+		return err
+	}
+	r := ParseRecord(p, b)
+	if strings.EqualFold(r.Status, "Accepted") {
+		return fmt.Errorf("%s is already accepted", p)
+	}
+	if err = a.Review.Check(r.Reviews); err != nil {
+		return fmt.Errorf("%s cannot be accepted yet: %w", p, err)
+	}
+	return c.UpdateStatus(p, "Accepted")
+}
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path"
+	"testing"
+)
+
+func Test_ParseReview(t *testing.T) {
+	type test struct {
+		line     string
+		expected Review
+	}
+	tests := []test{
+		{line: "- Requested from alice on 2024-01-02", expected: Review{Reviewer: "alice", State: ReviewRequested, Date: "2024-01-02"}},
+		{line: "- Approved by Bob Smith on 2024-01-03: ok: ship it", expected: Review{Reviewer: "Bob Smith", State: ReviewApproved, Date: "2024-01-03", Comment: "ok: ship it"}},
+		{line: "- Approved by carol", expected: Review{Reviewer: "carol", State: ReviewApproved}},
+		{line: "- Approved by al on 2024-01-02 15:04", expected: Review{Reviewer: "al", State: ReviewApproved, Date: "2024-01-02 15:04"}},
+		{line: "- Approved by al on 2024-01-02 15:04: lgtm", expected: Review{Reviewer: "al", State: ReviewApproved, Date: "2024-01-02 15:04", Comment: "lgtm"}},
+		{line: "- Approved by Team on call on 2024-01-02: based on load tests", expected: Review{Reviewer: "Team on call", State: ReviewApproved, Date: "2024-01-02", Comment: "based on load tests"}},
+		{line: "- Requested from Team on call", expected: Review{Reviewer: "Team on call", State: ReviewRequested}},
+		{line: "- Approved by dana on Jan 2, 2024", expected: Review{Reviewer: "dana", State: ReviewApproved, Date: "Jan 2, 2024"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			v, ok := parseReview(tt.line)
+			assert.True(t, ok)
+			assert.Equal(t, tt.expected, v)
+			assert.Equal(t, tt.line, v.markdown(), "reviews are written as they are read")
+		})
+	}
+	_, ok := parseReview("- Looked at by dave")
+	assert.False(t, ok)
+}
+
+func Test_ReviewPolicy(t *testing.T) {
+	requested := []Review{{Reviewer: "alice", State: ReviewRequested}, {Reviewer: "bob", State: ReviewRequested}}
+	alice := Review{Reviewer: "alice", State: ReviewApproved}
+	var none *ReviewPolicy
+	assert.Error(t, none.Check(requested), "one approval is needed by default")
+	assert.NoError(t, none.Check(append(requested, alice)))
+	two := &ReviewPolicy{Approvals: 2}
+	assert.Error(t, two.Check(append(requested, alice, alice)), "approvals count once per reviewer")
+	assert.NoError(t, two.Check(append(requested, alice, Review{Reviewer: "carol", State: ReviewApproved})))
+	all := &ReviewPolicy{AllRequested: true}
+	assert.EqualError(t, all.Check(append(requested, alice)), "still waiting for the approval of bob")
+}
+
+func Test_ReviewAndAccept(t *testing.T) {
+	startDir, workDir, err := setup()
+	handleHarnessErr(t, err)
+	defer cleanup(startDir, workDir)
+	for _, style := range []string{MetadataSections, MetadataFrontMatter} {
+		t.Run(style, func(t *testing.T) {
+			c := NewDefaultConfig()
+			c.Metadata = style
+			c.Review = &ReviewPolicy{Approvals: 2}
+			repoDir := path.Join(workDir, style)
+			handleHarnessErr(t, os.MkdirAll(repoDir, 0755))
+			handleHarnessErr(t, c.New(repoDir, map[string]string{"Title": "Use Postgres"}))
+			p := path.Join(repoDir, "001-use-postgres.md")
+			review := func(reviews ...Review) {
+				cs := NewChangeset()
+				assert.NoError(t, cs.Review(c.ADR, p, reviews...))
+				assert.NoError(t, cs.Apply())
+			}
+			accept := func() error {
+				cs := NewChangeset()
+				if err := cs.Accept(c.ADR, p); err != nil {
+					return err
+				}
+				return cs.Apply()
+			}
+			review(Review{Reviewer: "alice", State: ReviewRequested}, Review{Reviewer: "bob", State: ReviewRequested})
+			review(Review{Reviewer: "alice", State: ReviewApproved, Comment: "agreed"})
+			assert.EqualError(t, accept(), p+" cannot be accepted yet: 1 of 2 required approvals recorded")
+			assert.Error(t, NewChangeset().SetStatus(c.ADR, p, "accepted"), "setting the status is held to the policy too")
+			assert.NoError(t, NewChangeset().SetStatus(c.ADR, p, "Proposed"))
+			review(Review{Reviewer: "bob", State: ReviewApproved})
+			assert.NoError(t, accept())
+			b, err := os.ReadFile(p)
+			assert.NoError(t, err)
+			r := ParseRecord(p, b)
+			assert.Equal(t, "Accepted", r.Status)
+			assert.Len(t, r.Reviews, 4)
+			assert.Equal(t, "agreed", r.Reviews[2].Comment)
+			assert.NotEmpty(t, r.Reviews[2].Date)
+			assert.Error(t, accept(), "a record is accepted once")
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Revisit dates of a record, written as metadata like 'Review-By: 2025-06-01'. They are template variables named
+// without the hyphen (e.g. {{ .ReviewBy }}).
+const (
+	RevisitReviewBy = "Review-By"
+	RevisitExpires  = "Expires"
+)
+
+// Reasons a record is due
+const (
+	DueReview  = "review"
+	DueExpired = "expired"
+	DueStale   = "stale"
+)
+
+// defaultStaleAfter is how many days a record may stay Proposed when adr.revisit.staleafter is not set
+const defaultStaleAfter = 30
+
+// defaultExpiredStatus is the status 'adr sweep' gives expired records when adr.revisit.expired is not set
+const defaultExpiredStatus = "Deprecated"
+
+// RevisitPolicy is when records are due to be revisited and what 'adr sweep' does with them
+type RevisitPolicy struct {
+	// ReviewAfter dates new records for review this long after they are made, e.g. 6m or 1y, when set
+	ReviewAfter string `yaml:"reviewafter,omitempty"`
+	// StaleAfter is how many days a record may stay Proposed, 30 by default
+	StaleAfter int `yaml:"staleafter,omitempty"`
+	// Expired is the status 'adr sweep' gives records past their Expires date, Deprecated by default
+	Expired string `yaml:"expired,omitempty"`
+	// Stale is the status 'adr sweep' gives stale proposals, they are left alone when not set
+	Stale string `yaml:"stale,omitempty"`
+}
+
+// staleAfter returns how many days a record may stay Proposed
+func (p *RevisitPolicy) staleAfter() int {
+	if p == nil || p.StaleAfter <= 0 {
+		return defaultStaleAfter
+	}
+	return p.StaleAfter
+}
+
+// expired returns the status expired records are swept to
+func (p *RevisitPolicy) expired() string {
+	if p == nil || p.Expired == "" {
+		return defaultExpiredStatus
+	}
+	return p.Expired
+}
+
+// periodPattern matches periods such as '90d', '6w', '6m' or '1y'
+var periodPattern = regexp.MustCompile(`^(\d+)\s*([dwmy])$`)
+
+// revisitDate resolves a revisit date given as a date or as a period after from, e.g. '1y', in the record date format
+func (a *ADR) revisitDate(value string, from time.Time) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", nil
+	}
+	if m := periodPattern.FindStringSubmatch(strings.ToLower(value)); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		switch m[2] {
+		case "d":
+			from = from.AddDate(0, 0, n)
+		case "w":
+			from = from.AddDate(0, 0, 7*n)
+		case "m":
+			from = from.AddDate(0, n, 0)
+		case "y":
+			from = from.AddDate(n, 0, 0)
+		}
+		return from.Format(a.DateLayout()), nil
+	}
+	t, ok := ParseDate(value, a.DateLayout())
+	if !ok {
+		return "", fmt.Errorf("unable to read the revisit date '%s', expected a date like %s or a period like 90d, 6m or 1y", value, a.DateLayout())
+	}
+	return t.Format(a.DateLayout()), nil
+}
+
+// revisitKey returns the template variable of a revisit date, e.g. ReviewBy for Review-By
+func revisitKey(label string) string {
+	return strings.ReplaceAll(label, "-", "")
+}
+
+// Due is a record that needs attention: past its Review-By or Expires date, or Proposed for too long
+type Due struct {
+	Record *Record `json:"record"`
+	Reason string  `json:"reason"`
+	// Since is the date the record became due and Days how many days ago that was
+	Since string `json:"since"`
+	Days  int    `json:"days"`
+	// To is the status the record was swept to
+	To string `json:"to,omitempty"`
+}
+
+// resolved reports whether a status is final, so the record no longer needs revisiting
+func resolved(status string) bool {
+	s := strings.ToLower(strings.TrimSpace(status))
+	for _, final := range []string{"superseded", "deprecated", "rejected"} {
+		if strings.HasPrefix(s, final) {
+			return true
+		}
+	}
+	return false
+}
+
+// Due returns the records that are due on the day of now, ordered by how long they have been due. A record past
+// its Expires date is reported as expired rather than for review.
+func (a *ADR) Due(records []*Record, now time.Time) []Due {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	day := func(s string) (time.Time, bool) {
+		t, ok := ParseDate(s, a.DateLayout())
+		if !ok {
+			return t, false
+		}
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), true
+	}
+	due := make([]Due, 0)
+	add := func(r *Record, reason string, since time.Time) {
+		due = append(due, Due{Record: r, Reason: reason, Since: since.Format(a.DateLayout()), Days: int(today.Sub(since).Hours() / 24)})
+	}
+	for _, r := range records {
+		if resolved(r.Status) {
+			continue
+		}
+		if t, ok := day(r.Metadata[strings.ToLower(RevisitExpires)]); ok && !t.After(today) {
+			add(r, DueExpired, t)
+			continue
+		}
+		if t, ok := day(r.Metadata[strings.ToLower(RevisitReviewBy)]); ok && !t.After(today) {
+			add(r, DueReview, t)
+			continue
+		}
+		if strings.EqualFold(r.Status, "Proposed") {
+			if t, ok := day(r.Date); ok {
+				if stale := t.AddDate(0, 0, a.Revisit.staleAfter()); !stale.After(today) {
+					add(r, DueStale, stale)
+				}
+			}
+		}
+	}
+	sort.SliceStable(due, func(i, j int) bool {
+		return due[i].Days > due[j].Days
+	})
+	return due
+}
+
+// Sweep stages the status changes for the due records: expired records get the adr.revisit.expired status and
+// stale proposals the adr.revisit.stale status, when one is set. It returns the records it changed with their new
+// status.
+func (c *Changeset) Sweep(a *ADR, due []Due) ([]Due, error) {
+	var swept []Due
+	for _, d := range due {
+		to := ""
+		switch d.Reason {
+		case DueExpired:
+			to = a.Revisit.expired()
+		case DueStale:
+			if a.Revisit != nil {
+				to = a.Revisit.Stale
+			}
+		}
+		if to == "" || strings.EqualFold(d.Record.Status, to) {
+			continue
+		}
+		if err := c.UpdateStatus(d.Record.Path, to); err != nil {
+			return nil, err
+		}
+		d.To = to
+		swept = append(swept, d)
+	}
+	return swept, nil
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path"
+	"testing"
+	"time"
+)
+
+func Test_RevisitDate(t *testing.T) {
+	a := &ADR{}
+	from := time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC)
+	type test struct {
+		value    string
+		expected string
+	}
+	tests := []test{
+		{value: "", expected: ""},
+		{value: "90d", expected: "2024-04-30"},
+		{value: "2w", expected: "2024-02-14"},
+		{value: "1y", expected: "2025-01-31"},
+		{value: "2024-06-01", expected: "2024-06-01"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			d, err := a.revisitDate(tt.value, from)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, d)
+		})
+	}
+	_, err := a.revisitDate("soon", from)
+	assert.Error(t, err)
+	_, err = (&ADR{DateFormat: "02.01.2006"}).revisitDate("soon", from)
+	assert.ErrorContains(t, err, "a date like 02.01.2006", "the hint follows the configured date format")
+}
+
+func Test_DueAndSweep(t *testing.T) {
+	startDir, workDir, err := setup()
+	handleHarnessErr(t, err)
+	defer cleanup(startDir, workDir)
+	c := NewDefaultConfig()
+	c.Revisit = &RevisitPolicy{ReviewAfter: "1y", StaleAfter: 10, Stale: "Rejected"}
+	repoDir := path.Join(workDir, DefaultRepositoryDir)
+	add := func(values map[string]string) {
+		handleHarnessErr(t, c.New(repoDir, values))
+	}
+	add(map[string]string{"Title": "expiring", "Date": "2024-01-01", "Expires": "6m"})
+	add(map[string]string{"Title": "to review", "Date": "2023-01-01"})
+	add(map[string]string{"Title": "old proposal", "Date": "2024-05-01"})
+	add(map[string]string{"Title": "new proposal", "Date": "2024-06-25"})
+	add(map[string]string{"Title": "expired but superseded", "Date": "2023-01-01", "Expires": "2023-06-01"})
+	handleHarnessErr(t, UpdateStatus(path.Join(repoDir, "005-expired-but-superseded.md"), "Superseded"))
+	b, err := os.ReadFile(path.Join(repoDir, "001-expiring.md"))
+	handleHarnessErr(t, err)
+	assert.Contains(t, string(b), "Review-By: 2025-01-01\nExpires: 2024-07-01\n")
+	// begin test
+	records, err := LoadRecords(nil, repoDir)
+	assert.NoError(t, err)
+	now := time.Date(2024, 7, 1, 9, 0, 0, 0, time.UTC)
+	due := c.Due(records, now)
+	var got []string
+	for _, d := range due {
+		got = append(got, d.Record.ID+" "+d.Reason+" "+d.Since)
+	}
+	assert.Equal(t, []string{"002 review 2024-01-01", "003 stale 2024-05-11", "001 expired 2024-07-01"}, got)
+	assert.Equal(t, 182, due[0].Days)
+	assert.Equal(t, 0, due[2].Days, "a record is due on the day")
+
+	cs := NewChangeset()
+	swept, err := cs.Sweep(c.ADR, due)
+	assert.NoError(t, err)
+	assert.NoError(t, cs.Apply())
+	assert.Len(t, swept, 2, "records due for review are left alone")
+	records, err = LoadRecords(nil, repoDir)
+	assert.NoError(t, err)
+	assert.Equal(t, "Deprecated", records[0].Status)
+	assert.Equal(t, "Rejected", records[2].Status)
+	assert.Len(t, c.Due(records, now), 1)
+}
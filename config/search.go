@@ -0,0 +1,333 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	titleWeight    = 5
+	decisionWeight = 3
+	sectionWeight  = 1
+	maxSnippets    = 3
+	snippetWidth   = 120
+)
+
+// Query is a parsed search query. Free text terms must all appear in a record for it to match, the remaining fields
+// filter the records that are considered.
+type Query struct {
+	Terms    []string
+	Statuses []string
+	Sections []string
+	After    *time.Time
+	Before   *time.Time
+	// Fields filters on declared metadata fields, keyed by field name
+	Fields map[string][]string
+	// settings are those of the repository searched, for its date format
+	settings *ADR
+}
+
+// ParseQuery parses a query such as 'postgres status:accepted section:decision after:2025-01-01'. Terms and values
+// can be quoted to include spaces, e.g. '"event sourcing" section:"decision"'. The names of the given metadata fields
+// and the participant roles can be used as filters too, e.g. 'tags:billing deciders:alice'. Dates are read in the
+// date format of a, the settings of the repository to search, or in ISO 8601.
+func ParseQuery(q string, a *ADR) (*Query, error) {
+	query := &Query{Fields: make(map[string][]string), settings: a}
+	for _, token := range tokenize(q) {
+		key, value, found := strings.Cut(token, ":")
+		if !found || !isFieldName(key) || strings.HasPrefix(value, "//") {
+			term := strings.ToLower(strings.Trim(token, `"`))
+			if term == "" {
+				return nil, fmt.Errorf("empty search term '%s'", token)
+			}
+			query.Terms = append(query.Terms, term)
+			continue
+		}
+		value = strings.Trim(value, `"`)
+		switch strings.ToLower(key) {
+		case "status":
+			query.Statuses = append(query.Statuses, value)
+		case "section":
+			query.Sections = append(query.Sections, value)
+		case "after", "before":
+			t, ok := ParseDate(value, a.DateLayout())
+			if !ok {
+				return nil, fmt.Errorf("unable to read the date in '%s', expected a date like %s", token, a.DateLayout())
+			}
+			if strings.EqualFold(key, "after") {
+				query.After = &t
+			} else {
+				query.Before = &t
+			}
+		default:
+			if IsParticipantRole(key) {
+				role := strings.ToLower(key)
+				query.Fields[role] = append(query.Fields[role], value)
+				continue
+			}
+			f := a.Field(key)
+			if f == nil {
+				return nil, fmt.Errorf("unknown search field '%s', expected status, section, after, before, a participant role or a declared field", key)
+			}
+			query.Fields[f.Name] = append(query.Fields[f.Name], value)
+		}
+	}
+	return query, nil
+}
+
+// tokenize splits q on whitespace, keeping double quoted runs together
+func tokenize(q string) []string {
+	var tokens []string
+	var current strings.Builder
+	quoted := false
+	for _, r := range q {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			current.WriteRune(r)
+		case unicode.IsSpace(r) && !quoted:
+			if current.Len() > 0 {
+				tokens = append(tokens, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if current.Len() > 0 {
+		tokens = append(tokens, current.String())
+	}
+	return tokens
+}
+
+// isFieldName reports whether s looks like a query field rather than text that happens to contain a colon, URLs are
+// also told apart by the '//' following their colon
+func isFieldName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if !unicode.IsLetter(r) && r != '-' && r != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+// Match is a record that satisfied a query
+type Match struct {
+	Record   *Record    `json:"record"`
+	Score    int        `json:"score"`
+	Snippets []*Snippet `json:"snippets"`
+}
+
+// Snippet is a line of a record containing at least one search term. Highlights are the byte offsets of each term
+// within Text as [start, end) pairs.
+type Snippet struct {
+	Section    string   `json:"section,omitempty"`
+	Line       int      `json:"line"`
+	Text       string   `json:"text"`
+	Highlights [][2]int `json:"highlights"`
+}
+
+// Highlighted returns the snippet text with every highlight wrapped in before and after
+func (s *Snippet) Highlighted(before, after string) string {
+	var b strings.Builder
+	last := 0
+	for _, h := range s.Highlights {
+		b.WriteString(s.Text[last:h[0]])
+		b.WriteString(before + s.Text[h[0]:h[1]] + after)
+		last = h[1]
+	}
+	b.WriteString(s.Text[last:])
+	return b.String()
+}
+
+// Search returns the records matching q, best matches first, then by number and ID
+func Search(records []*Record, q *Query) []*Match {
+	var matches []*Match
+	for _, r := range records {
+		if !q.filter(r) {
+			continue
+		}
+		if m := q.match(r); m != nil {
+			matches = append(matches, m)
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		if matches[i].Record.Number != matches[j].Record.Number {
+			return matches[i].Record.Number < matches[j].Record.Number
+		}
+		return matches[i].Record.ID < matches[j].Record.ID
+	})
+	return matches
+}
+
+// Filter returns the records passing the non-text parts of the query, in their order. The terms are not matched.
+func (q *Query) Filter(records []*Record) []*Record {
+	var filtered []*Record
+	for _, r := range records {
+		if q.filter(r) {
+			filtered = append(filtered, r)
+		}
+	}
+	return filtered
+}
+
+// filter reports whether r passes the non-text parts of the query
+func (q *Query) filter(r *Record) bool {
+	if len(q.Statuses) > 0 && !containsFold(q.Statuses, r.Status) {
+		return false
+	}
+	for name, values := range q.Fields {
+		matched := false
+		for _, v := range values {
+			matched = matched || r.HasField(name, v)
+		}
+		if !matched {
+			return false
+		}
+	}
+	if q.After != nil || q.Before != nil {
+		created, ok := createdAt(q.settings, r)
+		if !ok || (q.After != nil && created.Before(*q.After)) || (q.Before != nil && created.After(*q.Before)) {
+			return false
+		}
+	}
+	return true
+}
+
+// match scores the text terms of the query against r, returning nil unless every term is found
+func (q *Query) match(r *Record) *Match {
+	m := &Match{Record: r}
+	found := make(map[string]bool)
+	consider := func(section string, line int, text string, weight int) {
+		var highlights [][2]int
+		for _, term := range q.Terms {
+			offsets := termOffsets(text, term)
+			if len(offsets) == 0 {
+				continue
+			}
+			found[term] = true
+			m.Score += len(offsets) * weight
+			highlights = append(highlights, offsets...)
+		}
+		if len(highlights) > 0 && len(m.Snippets) < maxSnippets {
+			m.Snippets = append(m.Snippets, newSnippet(section, line, text, highlights))
+		}
+	}
+	if len(q.Sections) == 0 {
+		consider("", r.titleLine, r.Title, titleWeight)
+	}
+	for _, s := range r.Sections {
+		if len(q.Sections) > 0 && !containsFold(q.Sections, s.Name) {
+			continue
+		}
+		weight := sectionWeight
+		if strings.EqualFold(s.Name, "Decision") {
+			weight = decisionWeight
+		}
+		for i, line := range s.lines {
+			consider(s.Name, s.Line+1+i, line, weight)
+		}
+	}
+	if len(found) < len(uniqueTerms(q.Terms)) {
+		return nil
+	}
+	if len(q.Terms) == 0 {
+		// a query with only filters matches everything that passed them
+		m.Score = 0
+	}
+	return m
+}
+
+// termOffsets returns the [start, end) offsets of every occurrence of term in s, ignoring case. Runes are folded one
+// by one, so the offsets are those of s even where its upper and lower case letters differ in length.
+func termOffsets(s, term string) [][2]int {
+	n := utf8.RuneCountInString(term)
+	if n == 0 {
+		return nil
+	}
+	var offsets [][2]int
+	for start := 0; start < len(s); {
+		end, count := start, 0
+		for ; end < len(s) && count < n; count++ {
+			_, size := utf8.DecodeRuneInString(s[end:])
+			end += size
+		}
+		if count < n {
+			break
+		}
+		if strings.EqualFold(s[start:end], term) {
+			offsets = append(offsets, [2]int{start, end})
+			start = end
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(s[start:])
+		start += size
+	}
+	return offsets
+}
+
+// newSnippet trims long lines to a window around the first highlight and merges overlapping highlights
+func newSnippet(section string, line int, text string, highlights [][2]int) *Snippet {
+	sort.Slice(highlights, func(i, j int) bool { return highlights[i][0] < highlights[j][0] })
+	start, end := 0, len(text)
+	if len(text) > snippetWidth {
+		start = highlights[0][0] - snippetWidth/4
+		if start < 0 {
+			start = 0
+		}
+		end = start + snippetWidth
+		if end > len(text) {
+			end = len(text)
+		}
+		// keep the window on rune boundaries
+		for start > 0 && !utf8.RuneStart(text[start]) {
+			start--
+		}
+		for end < len(text) && !utf8.RuneStart(text[end]) {
+			end++
+		}
+	}
+	s := &Snippet{Section: section, Line: line}
+	for _, h := range highlights {
+		if h[0] < start || h[1] > end {
+			continue
+		}
+		h = [2]int{h[0] - start, h[1] - start}
+		if n := len(s.Highlights); n > 0 && h[0] <= s.Highlights[n-1][1] {
+			if h[1] > s.Highlights[n-1][1] {
+				s.Highlights[n-1][1] = h[1]
+			}
+			continue
+		}
+		s.Highlights = append(s.Highlights, h)
+	}
+	s.Text = text[start:end]
+	return s
+}
+
+func uniqueTerms(terms []string) map[string]bool {
+	u := make(map[string]bool)
+	for _, t := range terms {
+		u[t] = true
+	}
+	return u
+}
+
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,126 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// searchRecords is a test helper with a small set of parsed records
+func searchRecords() []*Record {
+	return []*Record{
+		ParseRecord("001-use-postgres.md", []byte("# 001-use-postgres\nDate: 2024-06-01\n\n## Status\nAccepted\n\n## Context\nWe need a database.\n\n## Decision\nUse Postgres for billing.\n")),
+		ParseRecord("002-cache-layer.md", []byte("# 002-cache-layer\nDate: 2025-03-01\n\n## Status\nAccepted\n\n## Context\nPostgres is slow for reads.\n\n## Decision\nAdd Redis.\n")),
+		ParseRecord("003-event-sourcing.md", []byte("# 003-event-sourcing\nDate: 2025-04-01\n\n## Status\nProposed\n\n## Context\nAudit needs.\n\n## Decision\nUse event sourcing backed by postgres.\n")),
+	}
+}
+
+func Test_ParseQuery(t *testing.T) {
+	q, err := ParseQuery(`Postgres "event sourcing" status:accepted section:"Decision" after:2025-01-01 http://x`, NewDefaultConfig().ADR)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"postgres", "event sourcing", "http://x"}, q.Terms)
+	assert.Equal(t, []string{"accepted"}, q.Statuses)
+	assert.Equal(t, []string{"Decision"}, q.Sections)
+	assert.Equal(t, "2025-01-01", q.After.Format("2006-01-02"))
+	_, err = ParseQuery("colour:blue", NewDefaultConfig().ADR)
+	assert.Error(t, err, "unknown fields should be reported")
+	_, err = ParseQuery("after:yesterday", NewDefaultConfig().ADR)
+	assert.Error(t, err)
+	_, err = ParseQuery(`postgres ""`, NewDefaultConfig().ADR)
+	assert.Error(t, err, "an empty term would match everything")
+}
+
+func Test_SearchInTheConfiguredDateFormat(t *testing.T) {
+	a := NewDefaultConfig().ADR
+	a.DateFormat = "02.01.2006"
+	records := []*Record{
+		ParseRecord("001-old.md", []byte("# 001-old\nDate: 01.06.1999\n\n## Status\nAccepted\n")),
+		ParseRecord("002-new.md", []byte("# 002-new\nDate: 01.03.2025\n\n## Status\nAccepted\n")),
+	}
+	q, err := ParseQuery("after:01.01.2000", a)
+	assert.NoError(t, err)
+	matches := Search(records, q)
+	if assert.Len(t, matches, 1) {
+		assert.Equal(t, 2, matches[0].Record.Number)
+	}
+	q, err = ParseQuery("before:2000-01-01", a)
+	assert.NoError(t, err, "ISO 8601 dates are understood too")
+	assert.Len(t, Search(records, q), 1)
+}
+
+func Test_SearchRanking(t *testing.T) {
+	type test struct {
+		name     string
+		query    string
+		expected []int
+	}
+	tests := []test{
+		{name: "Title and decision rank above context", query: "postgres", expected: []int{1, 3, 2}},
+		{name: "Status filter", query: "postgres status:accepted", expected: []int{1, 2}},
+		{name: "Section filter", query: "postgres section:decision", expected: []int{1, 3}},
+		{name: "Date filter", query: "postgres after:2025-01-01", expected: []int{3, 2}},
+		{name: "All terms must match", query: "postgres redis", expected: []int{2}},
+		{name: "Phrases", query: `"event sourcing"`, expected: []int{3}},
+		{name: "No match", query: "mongodb", expected: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := ParseQuery(tt.query, NewDefaultConfig().ADR)
+			handleHarnessErr(t, err)
+			var actual []int
+			for _, m := range Search(searchRecords(), q) {
+				actual = append(actual, m.Record.Number)
+			}
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func Test_SearchSnippets(t *testing.T) {
+	q, err := ParseQuery("postgres section:decision", NewDefaultConfig().ADR)
+	handleHarnessErr(t, err)
+	// begin test
+	matches := Search(searchRecords(), q)
+	if assert.NotEmpty(t, matches) && assert.Len(t, matches[0].Snippets, 1) {
+		s := matches[0].Snippets[0]
+		assert.Equal(t, "Decision", s.Section)
+		assert.Equal(t, 11, s.Line)
+		assert.Equal(t, "Use **Postgres** for billing.", s.Highlighted("**", "**"))
+	}
+}
+
+func Test_SearchFoldsLettersOfAnotherLength(t *testing.T) {
+	// the lower case of Ⱥ is a byte longer than it
+	records := []*Record{ParseRecord("001-fonts.md", []byte("# 001-fonts\n\n## Decision\nRender Ⱥ with Postgres fonts.\n"))}
+	q, err := ParseQuery("postgres ⱥ", NewDefaultConfig().ADR)
+	handleHarnessErr(t, err)
+	// begin test
+	matches := Search(records, q)
+	if assert.Len(t, matches, 1) && assert.Len(t, matches[0].Snippets, 1) {
+		assert.Equal(t, "Render [Ⱥ] with [Postgres] fonts.", matches[0].Snippets[0].Highlighted("[", "]"))
+	}
+}
+
+func Test_QueryFilter(t *testing.T) {
+	q, err := ParseQuery("status:proposed status:superseded", NewDefaultConfig().ADR)
+	handleHarnessErr(t, err)
+	// begin test
+	filtered := q.Filter(searchRecords())
+	if assert.Len(t, filtered, 1) {
+		assert.Equal(t, "003-event-sourcing.md", filtered[0].Path)
+	}
+}
+
+func Test_SearchOrdersTiesByID(t *testing.T) {
+	records := []*Record{
+		ParseRecord("SEC-007-use-mtls.md", []byte("# SEC-007. Use mTLS\n\n## Decision\nUse mTLS.\n")),
+		ParseRecord("007-use-tls.md", []byte("# 007. Use TLS\n\n## Decision\nUse mTLS.\n")),
+	}
+	q, err := ParseQuery("mtls section:decision", NewDefaultConfig().ADR)
+	handleHarnessErr(t, err)
+	// begin test
+	matches := Search(records, q)
+	if assert.Len(t, matches, 2) {
+		assert.Equal(t, "007", matches[0].Record.ID)
+		assert.Equal(t, "SEC-007", matches[1].Record.ID)
+	}
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+	"strings"
+	"unicode"
+)
+
+// noTitle is the slug of a record without a title
+const noTitle = "no-title-given"
+
+// SlugRules configures how titles become the .Slug used in file names
+type SlugRules struct {
+	MaxLength int      `yaml:"maxlength,omitempty"` // cut longer slugs at a word boundary, no limit when 0
+	Separator string   `yaml:"separator,omitempty"` // between words, '-' by default
+	ASCII     bool     `yaml:"ascii,omitempty"`     // transliterate accented characters, e.g. 'é' to 'e'
+	StopWords []string `yaml:"stopwords,omitempty"` // words left out, e.g. a, an, the
+}
+
+// ligatures are letters that do not decompose into an ASCII letter and a mark
+var ligatures = strings.NewReplacer("ß", "ss", "æ", "ae", "œ", "oe", "ø", "o", "ł", "l", "đ", "d", "þ", "th")
+
+// Slugify turns a title into the slug used in file names following the configured slug rules: lower case words of
+// letters and digits joined by the separator
+func (a *ADR) Slugify(title string) string {
+	rules := a.Slug
+	if rules == nil {
+		rules = &SlugRules{}
+	}
+	sep := rules.Separator
+	if sep == "" {
+		sep = "-"
+	}
+	title = strings.ToLower(title)
+	if rules.ASCII {
+		title = transliterate(title)
+	}
+	words := strings.FieldsFunc(title, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	kept := words[:0:0]
+	for _, w := range words {
+		if !containsFold(rules.StopWords, w) {
+			kept = append(kept, w)
+		}
+	}
+	if len(kept) > 0 {
+		// a title of nothing but stop words keeps them
+		words = kept
+	}
+	if len(words) == 0 {
+		return noTitle
+	}
+	slug := words[0]
+	for _, w := range words[1:] {
+		if rules.MaxLength > 0 && len(slug)+len(sep)+len(w) > rules.MaxLength {
+			break
+		}
+		slug += sep + w
+	}
+	if rules.MaxLength > 0 && len(slug) > rules.MaxLength {
+		// a single long word is cut on a rune boundary
+		cut := []rune(slug)
+		for len(string(cut)) > rules.MaxLength {
+			cut = cut[:len(cut)-1]
+		}
+		slug = string(cut)
+	}
+	return slug
+}
+
+// transliterate strips accents from letters, e.g. 'crème brûlée' becomes 'creme brulee'
+func transliterate(s string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	out, _, err := transform.String(t, ligatures.Replace(s))
+	if err != nil {
+		return s
+	}
+	return out
+}
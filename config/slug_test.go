@@ -0,0 +1,56 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path"
+	"testing"
+)
+
+func Test_Slugify(t *testing.T) {
+	type test struct {
+		name     string
+		rules    *SlugRules
+		title    string
+		expected string
+	}
+	tests := []test{
+		{name: "Default", title: "Use Postgres for billing/payments!", expected: "use-postgres-for-billing-payments"},
+		{name: "Runs of symbols collapse", title: "  Cache -- reads  ", expected: "cache-reads"},
+		{name: "Empty", title: "?!", expected: noTitle},
+		{name: "Accents kept by default", title: "Crème brûlée", expected: "crème-brûlée"},
+		{name: "ASCII", rules: &SlugRules{ASCII: true}, title: "Crème brûlée straße", expected: "creme-brulee-strasse"},
+		{name: "Separator", rules: &SlugRules{Separator: "_"}, title: "Use Postgres", expected: "use_postgres"},
+		{name: "Stop words", rules: &SlugRules{StopWords: []string{"a", "the", "for"}}, title: "The case for a cache", expected: "case-cache"},
+		{name: "Only stop words", rules: &SlugRules{StopWords: []string{"the"}}, title: "The", expected: "the"},
+		{name: "Max length at a word boundary", rules: &SlugRules{MaxLength: 16}, title: "Use Postgres for billing", expected: "use-postgres-for"},
+		{name: "Max length within a word", rules: &SlugRules{MaxLength: 5}, title: "Internationalisation", expected: "inter"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &ADR{Slug: tt.rules}
+			assert.Equal(t, tt.expected, a.Slugify(tt.title))
+		})
+	}
+}
+
+func Test_NewKeepsTheTitleAsTyped(t *testing.T) {
+	startDir, workDir, err := setup()
+	handleHarnessErr(t, err)
+	defer cleanup(startDir, workDir)
+	c := NewDefaultConfig()
+	c.Slug = &SlugRules{StopWords: []string{"for"}}
+	repoDir := path.Join(workDir, DefaultRepositoryDir)
+	// begin test
+	assert.NoError(t, c.New(repoDir, map[string]string{"Title": "Use Postgres for billing"}))
+	p := path.Join(repoDir, "001-use-postgres-billing.md")
+	b, err := os.ReadFile(p)
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), "# 001. Use Postgres for billing\n")
+	assert.Equal(t, "Use Postgres for billing", ParseRecord(p, b).Title)
+
+	c.TitleTemplate = "{{ .Number }}-{{ .Title }}.md"
+	assert.NoError(t, c.New(repoDir, map[string]string{"Title": "Older Templates"}))
+	_, err = os.Stat(path.Join(repoDir, "002-older-templates.md"))
+	assert.NoError(t, err, ".Title is the slug in the title template")
+}
@@ -0,0 +1,167 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"github.com/fleetingclarity/adr/storage"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+)
+
+// defaultFileMode is used for files created by the adr tool
+const defaultFileMode os.FileMode = 0644
+
+// Apply writes the staged changes to disk as a single transaction. Every new file content is first written and
+// synced to a temporary file beside its destination, only once all of them are staged are they renamed into place.
+// If any step fails every file already touched is restored and no temporary files are left behind.
+func (c *Changeset) Apply() error {
+	paths := c.Paths()
+	if len(paths) == 0 {
+		return nil
+	}
+	fsys := fileSystem(c.FS)
+	temps := make(map[string]string)
+	defer func() {
+		for _, tmp := range temps {
+			_ = fsys.Remove(tmp)
+		}
+	}()
+	for _, p := range paths {
+		s := c.files[p]
+		if s.removed {
+			continue
+		}
+		tmp, err := writeTemp(fsys, p, s.content, s.mode)
+		if err != nil {
+			return fmt.Errorf("unable to stage %s: %w", p, err)
+		}
+		temps[p] = tmp
+	}
+	if c.Journal != "" {
+		if err := c.writeJournal(paths); err != nil {
+			return err
+		}
+	}
+	var done []string
+	for _, p := range paths {
+		var err error
+		if c.files[p].removed {
+			err = fsys.Remove(p)
+		} else {
+			err = fsys.Rename(temps[p], p)
+			if err == nil {
+				delete(temps, p)
+			}
+		}
+		if err != nil {
+			if rbErr := c.rollback(done); rbErr != nil {
+				return fmt.Errorf("unable to update %s: %v, rollback also failed: %w", p, err, rbErr)
+			}
+			if c.Journal != "" {
+				_ = fsys.Remove(c.Journal)
+			}
+			return fmt.Errorf("unable to update %s, all changes were rolled back: %w", p, err)
+		}
+		done = append(done, p)
+	}
+	if fsys == storage.OS {
+		syncDirs(paths)
+	}
+	return nil
+}
+
+// rollback restores the original state of the given files, most recent first
+func (c *Changeset) rollback(done []string) error {
+	var failed []string
+	for i := len(done) - 1; i >= 0; i-- {
+		p := done[i]
+		if err := restore(fileSystem(c.FS), p, c.files[p]); err != nil {
+			failed = append(failed, p)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("unable to restore %v", failed)
+	}
+	return nil
+}
+
+// restore puts back the content a staged file had before it was changed
+func restore(fsys storage.FS, p string, s *stagedFile) error {
+	if !s.existed {
+		err := fsys.Remove(p)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	tmp, err := writeTemp(fsys, p, s.original, s.mode)
+	if err != nil {
+		return err
+	}
+	if err = fsys.Rename(tmp, p); err != nil {
+		_ = fsys.Remove(tmp)
+	}
+	return err
+}
+
+// tempCount numbers the temporary files of this process on file systems other than the disk
+var tempCount uint64
+
+// writeTemp writes b to a temporary file in the same directory as p and returns its path. On the disk the file is
+// created exclusively with os.CreateTemp, so nothing already at its name is followed or overwritten, and synced.
+func writeTemp(fsys storage.FS, p string, b []byte, mode os.FileMode) (string, error) {
+	dir := filepath.Dir(p)
+	if err := fsys.MkdirAll(dir, os.ModePerm); err != nil {
+		return "", err
+	}
+	if fsys == storage.OS {
+		return createTemp(dir, p, b, mode)
+	}
+	tmp := filepath.Join(dir, fmt.Sprintf(".%s.%d-%d.tmp", filepath.Base(p), os.Getpid(), atomic.AddUint64(&tempCount, 1)))
+	if err := fsys.WriteFile(tmp, b, mode); err != nil {
+		_ = fsys.Remove(tmp)
+		return "", err
+	}
+	return tmp, nil
+}
+
+// createTemp writes b to a new temporary file on the disk in dir, named after p
+func createTemp(dir, p string, b []byte, mode os.FileMode) (string, error) {
+	f, err := os.CreateTemp(dir, "."+filepath.Base(p)+".*.tmp")
+	if err != nil {
+		return "", err
+	}
+	tmp := f.Name()
+	_, err = f.Write(b)
+	if err == nil {
+		err = f.Chmod(mode)
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if cErr := f.Close(); err == nil {
+		err = cErr
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return "", err
+	}
+	return tmp, nil
+}
+
+// syncDirs flushes the directory entries of the renamed files, errors are ignored as not every platform supports it
+func syncDirs(paths []string) {
+	seen := make(map[string]bool)
+	for _, p := range paths {
+		dir := filepath.Dir(p)
+		if seen[dir] {
+			continue
+		}
+		seen[dir] = true
+		if d, err := os.Open(dir); err == nil {
+			_ = d.Sync()
+			_ = d.Close()
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package config
+
+import (
+	"errors"
+	"github.com/fleetingclarity/adr/storage"
+	"github.com/stretchr/testify/assert"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+)
+
+func Test_ApplyRollsBackOnFailure(t *testing.T) {
+	startDir, workDir, err := setup()
+	handleHarnessErr(t, err)
+	defer cleanup(startDir, workDir)
+	repoDir := path.Join(workDir, DefaultRepositoryDir)
+	first := path.Join(repoDir, "001-first.md")
+	second := path.Join(repoDir, "002-second.md")
+	handleHarnessErr(t, writeAndClose(first, "original\n"))
+	cs := NewChangeset()
+	handleHarnessErr(t, cs.Write(first, []byte("changed\n")))
+	handleHarnessErr(t, cs.Write(second, []byte("new\n")))
+	// a directory in the way makes the second rename fail after the first has been applied
+	handleHarnessErr(t, os.MkdirAll(path.Join(second, "blocker"), os.ModePerm))
+	// begin test
+	err = cs.Apply()
+	assert.Error(t, err)
+	actual, err := os.ReadFile(first)
+	assert.NoError(t, err)
+	assert.Equal(t, "original\n", string(actual), "the first file should have been rolled back")
+	leftovers, err := filepath.Glob(path.Join(repoDir, ".*.tmp"))
+	assert.NoError(t, err)
+	assert.Empty(t, leftovers, "no temporary files should be left behind")
+}
+
+func Test_ApplyLeavesNoTempFiles(t *testing.T) {
+	startDir, workDir, err := setup()
+	handleHarnessErr(t, err)
+	defer cleanup(startDir, workDir)
+	c := NewDefaultConfig()
+	repoDir := path.Join(workDir, DefaultRepositoryDir)
+	handleHarnessErr(t, c.New(repoDir, map[string]string{"Title": "first"}))
+	handleHarnessErr(t, c.New(repoDir, map[string]string{"Title": "second"}))
+	// begin test
+	assert.NoError(t, Supersede(&LinkPair{SourceNum: 1, TargetNum: 2, RepoDir: repoDir}))
+	entries, err := os.ReadDir(repoDir)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2, "only the two records should exist")
+}
+
+func Test_Undo(t *testing.T) {
+	startDir, workDir, err := setup()
+	handleHarnessErr(t, err)
+	defer cleanup(startDir, workDir)
+	c := NewDefaultConfig()
+	repoDir := path.Join(workDir, DefaultRepositoryDir)
+	handleHarnessErr(t, c.New(repoDir, map[string]string{"Title": "first"}))
+	handleHarnessErr(t, c.New(repoDir, map[string]string{"Title": "second"}))
+	before, err := os.ReadFile(path.Join(repoDir, "001-first.md"))
+	handleHarnessErr(t, err)
+	cs := NewChangeset()
+	cs.Operation = "adr supersede 1 2"
+	cs.Journal = c.JournalPath()
+	handleHarnessErr(t, cs.Supersede(&LinkPair{SourceNum: 1, TargetNum: 2, RepoDir: repoDir}))
+	handleHarnessErr(t, cs.Apply())
+	// begin test
+	undo := NewChangeset()
+	op, err := undo.Undo(c.JournalPath(), false)
+	assert.NoError(t, err)
+	assert.Equal(t, "adr supersede 1 2", op)
+	assert.Len(t, undo.Paths(), 2, "both superseded files should be restored")
+	assert.NoError(t, undo.Apply())
+	after, err := os.ReadFile(path.Join(repoDir, "001-first.md"))
+	assert.NoError(t, err)
+	assert.Equal(t, string(before), string(after))
+}
+
+func Test_UndoInMemory(t *testing.T) {
+	m := storage.NewMemory()
+	handleHarnessErr(t, m.MkdirAll("decisions", 0755))
+	handleHarnessErr(t, m.WriteFile("decisions/001-first.md", []byte("# 001. first\n"), 0644))
+	cs := NewChangeset()
+	cs.FS, cs.Operation, cs.Journal = m, "adr rename 1 second", DefaultJournalName
+	handleHarnessErr(t, cs.Write("decisions/001-first.md", []byte("# 001. second\n")))
+	handleHarnessErr(t, cs.Apply())
+	// begin test
+	undo := NewChangeset()
+	undo.FS = m
+	_, err := undo.Undo(DefaultJournalName, false)
+	assert.NoError(t, err)
+	assert.NoError(t, undo.Apply())
+	assert.NoError(t, undo.RemoveJournal(DefaultJournalName))
+	_, err = m.Stat(DefaultJournalName)
+	assert.True(t, errors.Is(err, fs.ErrNotExist), "the journal is removed from the file system it was read from")
+	b, err := m.ReadFile("decisions/001-first.md")
+	assert.NoError(t, err)
+	assert.Equal(t, "# 001. first\n", string(b))
+}
+
+func Test_UndoRefusesWhenFileChanged(t *testing.T) {
+	startDir, workDir, err := setup()
+	handleHarnessErr(t, err)
+	defer cleanup(startDir, workDir)
+	c := NewDefaultConfig()
+	repoDir := path.Join(workDir, DefaultRepositoryDir)
+	cs := NewChangeset()
+	cs.Journal = c.JournalPath()
+	p, err := cs.New(c.ADR, repoDir, map[string]string{"Title": "first"})
+	handleHarnessErr(t, err)
+	handleHarnessErr(t, cs.Apply())
+	handleHarnessErr(t, writeAndClose(p, "edited by hand\n"))
+	// begin test
+	_, err = NewChangeset().Undo(c.JournalPath(), false)
+	assert.Error(t, err, "undo should not clobber later edits")
+	undo := NewChangeset()
+	_, err = undo.Undo(c.JournalPath(), true)
+	assert.NoError(t, err)
+	assert.NoError(t, undo.Apply())
+	_, err = os.Stat(p)
+	assert.ErrorIs(t, err, os.ErrNotExist, "undoing an add should remove the new record")
+}
+
+func Test_ApplyToMemory(t *testing.T) {
+	m := storage.NewMemory()
+	handleHarnessErr(t, m.MkdirAll(DefaultRepositoryDir, 0755))
+	a := NewDefaultConfig().ADR
+	for _, title := range []string{"first", "second"} {
+		cs := NewChangeset()
+		cs.FS = m
+		_, err := cs.New(a, DefaultRepositoryDir, map[string]string{"Title": title, "Date": "2024-01-02"})
+		handleHarnessErr(t, err)
+		handleHarnessErr(t, cs.Apply())
+	}
+	// begin test
+	cs := NewChangeset()
+	cs.FS = m
+	cs.Journal = DefaultJournalName
+	assert.NoError(t, cs.Supersede(&LinkPair{SourceNum: 1, TargetNum: 2, RepoDir: DefaultRepositoryDir}))
+	assert.NoError(t, cs.Apply())
+	records, err := LoadRecords(m, DefaultRepositoryDir)
+	assert.NoError(t, err)
+	if assert.Len(t, records, 2) {
+		assert.Equal(t, "Superseded", records[0].Status)
+		assert.Equal(t, []Relation{{Rel: "Supersedes", Target: "001-first.md"}}, records[1].Links)
+	}
+	entries, err := m.ReadDir(DefaultRepositoryDir)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2, "no temporary files should be left behind")
+	_, err = os.Stat(DefaultJournalName)
+	assert.True(t, errors.Is(err, fs.ErrNotExist), "the journal is kept in the same file system")
+
+	undo := NewChangeset()
+	undo.FS = m
+	_, err = undo.Undo(DefaultJournalName, false)
+	assert.NoError(t, err)
+	assert.NoError(t, undo.Apply())
+	records, err = LoadRecords(m, DefaultRepositoryDir)
+	assert.NoError(t, err)
+	assert.Equal(t, "Proposed", records[0].Status)
+	assert.Empty(t, records[1].Links)
+}
+
+func Test_ApplyToReadOnly(t *testing.T) {
+	m := storage.NewMemory()
+	handleHarnessErr(t, m.MkdirAll(DefaultRepositoryDir, 0755))
+	handleHarnessErr(t, m.WriteFile(path.Join(DefaultRepositoryDir, "001-first.md"), []byte("# 1. first\n\n## Status\nProposed\n"), 0644))
+	cs := NewChangeset()
+	cs.FS = readOnly{m}
+	handleHarnessErr(t, cs.UpdateStatus(path.Join(DefaultRepositoryDir, "001-first.md"), "Accepted"))
+	// begin test
+	err := cs.Apply()
+	assert.True(t, errors.Is(err, storage.ErrReadOnly))
+	b, err := m.ReadFile(path.Join(DefaultRepositoryDir, "001-first.md"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), "Proposed")
+}
+
+// readOnly refuses every write to a file system
+type readOnly struct {
+	*storage.Memory
+}
+
+func (readOnly) WriteFile(name string, _ []byte, _ fs.FileMode) error {
+	return &fs.PathError{Op: "write", Path: name, Err: storage.ErrReadOnly}
+}
@@ -0,0 +1,139 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// allSections stands for every section of a record but Status and Review in adr.frozen
+const allSections = "*"
+
+// defaultFrozen freezes every section once a record is accepted, and keeps it frozen when it is superseded or
+// deprecated later
+var defaultFrozen = map[string][]string{
+	"accepted":   {allSections},
+	"superseded": {allSections},
+	"deprecated": {allSections},
+}
+
+// frozenPolicy returns the configured adr.frozen policy, or the default one
+func (a *ADR) frozenPolicy() map[string][]string {
+	if a.Frozen == nil {
+		return defaultFrozen
+	}
+	return a.Frozen
+}
+
+// frozenSections returns the sections of record r that may no longer change once it has the given status. Statuses
+// match the policy by their first word, so 'Superseded by [2. X](0002-x.md)' is superseded.
+func (a *ADR) frozenSections(status string, r *Record) []string {
+	word := strings.ToLower(strings.TrimSpace(status))
+	if i := strings.IndexAny(word, " \t"); i >= 0 {
+		word = word[:i]
+	}
+	var frozen []string
+	for key, sections := range a.frozenPolicy() {
+		if word == "" || !strings.EqualFold(key, word) {
+			continue
+		}
+		for _, name := range sections {
+			if name != allSections {
+				frozen = append(frozen, name)
+				continue
+			}
+			for _, s := range r.Sections {
+				if !strings.EqualFold(s.Name, "Status") && !strings.EqualFold(s.Name, reviewSection) {
+					frozen = append(frozen, s.Name)
+				}
+			}
+		}
+	}
+	return frozen
+}
+
+// sectionText returns the content of a section without trailing spaces and surrounding blank lines, so only real
+// edits count as changes
+func sectionText(s *Section) string {
+	if s == nil {
+		return ""
+	}
+	var lines []string
+	for _, line := range s.lines {
+		lines = append(lines, strings.TrimRight(line, " \t"))
+	}
+	return strings.Trim(strings.Join(lines, "\n"), "\n")
+}
+
+// FrozenChanges reports the sections of the record at p that its status in before freezes and that differ in after
+func (a *ADR) FrozenChanges(p string, before, after []byte) []Finding {
+	old := ParseRecord(p, before)
+	return a.frozenChanges(old, ParseRecord(p, after), old.Status, "")
+}
+
+// frozenChanges reports the sections frozen by status that differ between records old and r, since is the commit
+// old is from when comparing with history
+func (a *ADR) frozenChanges(old, r *Record, status, since string) []Finding {
+	var findings []Finding
+	seen := make(map[string]bool)
+	for _, name := range a.frozenSections(status, old) {
+		if seen[strings.ToLower(name)] {
+			continue
+		}
+		seen[strings.ToLower(name)] = true
+		s := r.Section(name)
+		if sectionText(old.Section(name)) == sectionText(s) {
+			continue
+		}
+		line := r.titleLine
+		if s != nil {
+			line = s.Line
+		}
+		if line < 1 {
+			line = 1
+		}
+		msg := fmt.Sprintf("the %s section of an %s record cannot change, supersede it instead", name, status)
+		if since != "" {
+			msg = fmt.Sprintf("the %s section changed after the record became %s in %s", name, status, since)
+		}
+		findings = append(findings, Finding{Path: r.Path, Line: line, Column: 1, Rule: "frozen", Severity: SeverityError, Message: msg})
+	}
+	return findings
+}
+
+// Verify compares the record at p with its content at each commit where it first reached a status that freezes
+// sections, e.g. where it became Accepted, and reports the frozen sections that changed since. Records that were
+// never committed have nothing to compare with.
+func (c *Config) Verify(a *ADR, p string) ([]Finding, error) {
+	h, err := c.History(p)
+	if err != nil || h == nil {
+		return nil, err
+	}
+	b, err := fileSystem(c.FS).ReadFile(p)
+	if err != nil {
+		return nil, err
+	}
+	r := ParseRecord(p, b)
+	var findings []Finding
+	reached := make(map[string]bool)
+	for _, sc := range h.StatusChanges {
+		key := strings.ToLower(sc.Status)
+		if reached[key] {
+			continue
+		}
+		reached[key] = true
+		then, err := c.gitShow(sc.Commit + ":" + sc.path)
+		if err != nil {
+			return nil, err
+		}
+		short := sc.Commit
+		if len(short) > 7 {
+			short = short[:7]
+		}
+		findings = append(findings, a.frozenChanges(ParseRecord(p, then), r, sc.Status, short)...)
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].Line < findings[j].Line
+	})
+	return findings, nil
+}
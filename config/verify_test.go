@@ -0,0 +1,66 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path"
+	"strings"
+	"testing"
+)
+
+func Test_FrozenSections(t *testing.T) {
+	r := ParseRecord("001-a.md", []byte("# 001. A\n\n## Status\nAccepted\n\n## Context\nc\n\n## Decision\nd\n\n## Review\n- Approved by alice\n"))
+	a := &ADR{}
+	assert.Equal(t, []string{"Context", "Decision"}, a.frozenSections("Accepted", r), "every section but Status and Review by default")
+	assert.Equal(t, []string{"Context", "Decision"}, a.frozenSections("Superseded by [2. B](0002-b.md)", r))
+	assert.Empty(t, a.frozenSections("Proposed", r))
+	a.Frozen = map[string][]string{"accepted": {"Decision"}}
+	assert.Equal(t, []string{"Decision"}, a.frozenSections("accepted", r))
+	assert.Empty(t, a.frozenSections("Superseded", r))
+}
+
+func Test_Verify(t *testing.T) {
+	startDir, workDir, err := setup()
+	handleHarnessErr(t, err)
+	defer cleanup(startDir, workDir)
+	setupGit(t, workDir)
+	c := NewDefaultConfig()
+	c.WorkingDirectory = workDir
+	c.Frozen = map[string][]string{"accepted": {"Decision"}}
+	repoDir := DefaultRepositoryDir
+	p := path.Join(repoDir, "001-use-postgres.md")
+	commit := func(msg string) {
+		_, err := runGit(workDir, "add", "--all")
+		handleHarnessErr(t, err)
+		_, err = runGit(workDir, "commit", "--quiet", "--message", msg)
+		handleHarnessErr(t, err)
+	}
+	replace := func(from, to string) {
+		b, err := os.ReadFile(p)
+		handleHarnessErr(t, err)
+		handleHarnessErr(t, os.WriteFile(p, []byte(strings.Replace(string(b), from, to, 1)), 0644))
+	}
+	handleHarnessErr(t, c.New(repoDir, map[string]string{"Title": "Use Postgres"}))
+	commit("propose")
+	replace("Describe the decision", "Use Postgres. Describe the decision")
+	commit("draft")
+	handleHarnessErr(t, UpdateStatus(p, "Accepted"))
+	commit("accept")
+	// begin test
+	findings, err := c.Verify(c.ADR, p)
+	assert.NoError(t, err)
+	assert.Empty(t, findings, "changes before acceptance are fine")
+	replace("Describe the environment", "More context. Describe the environment")
+	handleHarnessErr(t, Link(&LinkPair{SourcePath: p, TargetPath: p, SourceMsg: "see", BackMsg: "see"}))
+	commit("context and links")
+	findings, err = c.Verify(c.ADR, p)
+	assert.NoError(t, err)
+	assert.Empty(t, findings, "the policy only freezes the Decision")
+	replace("Use Postgres.", "Use MySQL.")
+	findings, err = c.Verify(c.ADR, p)
+	assert.NoError(t, err)
+	if assert.Len(t, findings, 1) {
+		assert.Equal(t, "frozen", findings[0].Rule)
+		assert.Contains(t, findings[0].Message, "the Decision section changed after the record became Accepted in ")
+	}
+}
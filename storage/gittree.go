@@ -0,0 +1,148 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing/fstest"
+)
+
+// GitTree is a read-only file system over a tree of a git repository, e.g. HEAD, a branch or a tag, read from the
+// object store without checking it out. Names are relative to the directory the tree was read in, absolute names
+// inside that directory work too. The write operations return ErrReadOnly.
+type GitTree struct {
+	dir   string
+	files fstest.MapFS
+}
+
+// NewGitTree reads the tree of rev as seen from dir, a directory of a git working tree, limited to the given paths
+// (relative to dir) when there are any. The content of every file is read up front.
+func NewGitTree(dir, rev string, paths ...string) (*GitTree, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	t := &GitTree{dir: abs, files: make(fstest.MapFS)}
+	list := exec.Command("git", append([]string{"ls-tree", "-r", "-t", "-z", rev, "--"}, paths...)...)
+	list.Dir = abs
+	out, err := list.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git ls-tree %s: %v", rev, err)
+	}
+	blobs := make(map[string]string)
+	var objects []string
+	for _, entry := range strings.Split(string(out), "\x00") {
+		// <mode> SP <type> SP <object> TAB <file>
+		meta, name, ok := strings.Cut(entry, "\t")
+		fields := strings.Fields(meta)
+		if !ok || len(fields) != 3 {
+			continue
+		}
+		switch fields[1] {
+		case "tree":
+			t.files[name] = &fstest.MapFile{Mode: fs.ModeDir | 0755}
+		case "blob":
+			mode := fs.FileMode(0644)
+			if fields[0] == "100755" {
+				mode = 0755
+			}
+			t.files[name] = &fstest.MapFile{Mode: mode}
+			blobs[name] = fields[2]
+			objects = append(objects, fields[2])
+		}
+	}
+	contents, err := catFiles(abs, objects)
+	if err != nil {
+		return nil, err
+	}
+	for name, object := range blobs {
+		t.files[name].Data = contents[object]
+	}
+	return t, nil
+}
+
+// catFiles reads the content of the given blobs with a single git cat-file
+func catFiles(dir string, objects []string) (map[string][]byte, error) {
+	contents := make(map[string][]byte)
+	if len(objects) == 0 {
+		return contents, nil
+	}
+	cat := exec.Command("git", "cat-file", "--batch")
+	cat.Dir = dir
+	cat.Stdin = strings.NewReader(strings.Join(objects, "\n") + "\n")
+	out, err := cat.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git cat-file: %v", err)
+	}
+	r := bufio.NewReader(bytes.NewReader(out))
+	for range objects {
+		// <object> SP <type> SP <size> LF <contents> LF
+		header, err := r.ReadString('\n')
+		if err != nil {
+			return nil, fmt.Errorf("git cat-file: %v", err)
+		}
+		fields := strings.Fields(header)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("git cat-file: unexpected output '%s'", strings.TrimSpace(header))
+		}
+		size, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("git cat-file: unexpected output '%s'", strings.TrimSpace(header))
+		}
+		b := make([]byte, size+1)
+		if _, err = io.ReadFull(r, b); err != nil {
+			return nil, fmt.Errorf("git cat-file: %v", err)
+		}
+		contents[fields[0]] = b[:size]
+	}
+	return contents, nil
+}
+
+// name turns a name into a path of the tree, absolute names are made relative to the directory it was read in
+func (t *GitTree) name(name string) string {
+	if filepath.IsAbs(name) {
+		if rel, err := filepath.Rel(t.dir, name); err == nil {
+			name = rel
+		}
+	}
+	return clean(name)
+}
+
+func (t *GitTree) Open(name string) (fs.File, error) {
+	return t.files.Open(t.name(name))
+}
+
+func (t *GitTree) Stat(name string) (fs.FileInfo, error) {
+	return t.files.Stat(t.name(name))
+}
+
+func (t *GitTree) ReadFile(name string) ([]byte, error) {
+	return t.files.ReadFile(t.name(name))
+}
+
+func (t *GitTree) ReadDir(name string) ([]fs.DirEntry, error) {
+	return t.files.ReadDir(t.name(name))
+}
+
+func (t *GitTree) WriteFile(name string, _ []byte, _ fs.FileMode) error {
+	return &fs.PathError{Op: "write", Path: name, Err: ErrReadOnly}
+}
+
+func (t *GitTree) Remove(name string) error {
+	return &fs.PathError{Op: "remove", Path: name, Err: ErrReadOnly}
+}
+
+func (t *GitTree) Rename(oldname, newname string) error {
+	return &os.LinkError{Op: "rename", Old: oldname, New: newname, Err: ErrReadOnly}
+}
+
+func (t *GitTree) MkdirAll(name string, _ fs.FileMode) error {
+	return &fs.PathError{Op: "mkdir", Path: name, Err: ErrReadOnly}
+}
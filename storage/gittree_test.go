@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// git runs a git command in dir for a test
+func git(t *testing.T, dir string, args ...string) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+}
+
+func Test_GitTree(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	git(t, dir, "init", "--quiet")
+	git(t, dir, "config", "user.name", "Test Author")
+	git(t, dir, "config", "user.email", "author@example.com")
+	git(t, dir, "config", "commit.gpgsign", "false")
+	decisions := filepath.Join(dir, "docs", "decisions")
+	assert.NoError(t, os.MkdirAll(decisions, 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(decisions, "001-a.md"), []byte("# 1. A\n"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "run.sh"), []byte("#!/bin/sh\n"), 0755))
+	git(t, dir, "add", ".")
+	git(t, dir, "commit", "--quiet", "-m", "first")
+	// changes after the commit are not in its tree
+	assert.NoError(t, os.WriteFile(filepath.Join(decisions, "001-a.md"), []byte("# 1. A, edited\n"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(decisions, "002-b.md"), []byte("# 2. B\n"), 0644))
+
+	tree, err := NewGitTree(dir, "HEAD")
+	assert.NoError(t, err)
+	b, err := tree.ReadFile("docs/decisions/001-a.md")
+	assert.NoError(t, err)
+	assert.Equal(t, "# 1. A\n", string(b))
+	b, err = tree.ReadFile(filepath.Join(decisions, "001-a.md"))
+	assert.NoError(t, err, "absolute names inside the directory are read too")
+	assert.Equal(t, "# 1. A\n", string(b))
+	_, err = tree.Stat("docs/decisions/002-b.md")
+	assert.True(t, errors.Is(err, fs.ErrNotExist))
+	fi, err := tree.Stat("run.sh")
+	assert.NoError(t, err)
+	assert.Equal(t, fs.FileMode(0755), fi.Mode())
+	fi, err = tree.Stat("docs")
+	assert.NoError(t, err)
+	assert.True(t, fi.IsDir())
+
+	var walked []string
+	assert.NoError(t, WalkDir(tree, "docs", func(p string, d fs.DirEntry, err error) error {
+		walked = append(walked, p)
+		return err
+	}))
+	assert.Equal(t, []string{"docs", "docs/decisions", "docs/decisions/001-a.md"}, walked)
+
+	sub, err := NewGitTree(decisions, "HEAD")
+	assert.NoError(t, err)
+	b, err = sub.ReadFile("001-a.md")
+	assert.NoError(t, err, "names are relative to the directory the tree was read in")
+	assert.Equal(t, "# 1. A\n", string(b))
+	limited, err := NewGitTree(dir, "HEAD", "docs")
+	assert.NoError(t, err)
+	_, err = limited.Stat("run.sh")
+	assert.True(t, errors.Is(err, fs.ErrNotExist))
+
+	assert.True(t, errors.Is(tree.WriteFile("docs/decisions/002-b.md", nil, 0644), ErrReadOnly))
+	assert.True(t, errors.Is(tree.Remove("run.sh"), ErrReadOnly))
+	assert.True(t, errors.Is(tree.Rename("run.sh", "go.sh"), ErrReadOnly))
+	assert.True(t, errors.Is(tree.MkdirAll("x", 0755), ErrReadOnly))
+
+	_, err = NewGitTree(dir, "no-such-branch")
+	assert.Error(t, err)
+}
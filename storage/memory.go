@@ -0,0 +1,162 @@
+package storage
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing/fstest"
+	"time"
+)
+
+// Memory is a file system kept in memory, e.g. to test operations without temporary directories or to preview them.
+// Absolute names are taken relative to its root, so '/docs/decisions' and 'docs/decisions' are the same directory.
+// It is safe for concurrent use.
+type Memory struct {
+	mu    sync.RWMutex
+	files fstest.MapFS
+}
+
+// NewMemory returns an empty Memory file system
+func NewMemory() *Memory {
+	return &Memory{files: make(fstest.MapFS)}
+}
+
+// clean turns a name into an io/fs path, '.' for the root
+func clean(name string) string {
+	name = strings.TrimLeft(path.Clean(filepath.ToSlash(name)), "/")
+	if name == "" {
+		return "."
+	}
+	return name
+}
+
+func (m *Memory) Open(name string) (fs.File, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.files.Open(clean(name))
+}
+
+func (m *Memory) Stat(name string) (fs.FileInfo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.files.Stat(clean(name))
+}
+
+func (m *Memory) ReadFile(name string) ([]byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.files.ReadFile(clean(name))
+}
+
+func (m *Memory) ReadDir(name string) ([]fs.DirEntry, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.files.ReadDir(clean(name))
+}
+
+// isDir reports whether name is a directory, the caller holds the lock
+func (m *Memory) isDir(name string) bool {
+	if name == "." {
+		return true
+	}
+	fi, err := m.files.Stat(name)
+	return err == nil && fi.IsDir()
+}
+
+// exists reports whether name is a file or directory, the caller holds the lock
+func (m *Memory) exists(name string) bool {
+	_, err := m.files.Stat(name)
+	return err == nil
+}
+
+func (m *Memory) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p := clean(name)
+	if !m.isDir(path.Dir(p)) {
+		return &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	if m.isDir(p) {
+		return &fs.PathError{Op: "open", Path: name, Err: errors.New("is a directory")}
+	}
+	m.files[p] = &fstest.MapFile{Data: append([]byte(nil), data...), Mode: perm.Perm(), ModTime: time.Now()}
+	return nil
+}
+
+// children returns the names below the directory p, the caller holds the lock
+func (m *Memory) children(p string) []string {
+	var names []string
+	for n := range m.files {
+		if strings.HasPrefix(n, p+"/") {
+			names = append(names, n)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (m *Memory) Remove(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p := clean(name)
+	if p == "." || !m.exists(p) {
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrNotExist}
+	}
+	if len(m.children(p)) > 0 {
+		return &fs.PathError{Op: "remove", Path: name, Err: errors.New("directory not empty")}
+	}
+	delete(m.files, p)
+	return nil
+}
+
+func (m *Memory) Rename(oldname, newname string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	from, to := clean(oldname), clean(newname)
+	if from == "." || !m.exists(from) {
+		return &os.LinkError{Op: "rename", Old: oldname, New: newname, Err: fs.ErrNotExist}
+	}
+	if !m.isDir(path.Dir(to)) {
+		return &os.LinkError{Op: "rename", Old: oldname, New: newname, Err: fs.ErrNotExist}
+	}
+	if m.isDir(to) {
+		return &os.LinkError{Op: "rename", Old: oldname, New: newname, Err: fs.ErrExist}
+	}
+	if from == to {
+		return nil
+	}
+	if f, ok := m.files[from]; ok {
+		delete(m.files, from)
+		m.files[to] = f
+	}
+	for _, n := range m.children(from) {
+		m.files[to+strings.TrimPrefix(n, from)] = m.files[n]
+		delete(m.files, n)
+	}
+	return nil
+}
+
+func (m *Memory) MkdirAll(name string, perm fs.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p := clean(name)
+	var dirs []string
+	for d := p; d != "."; d = path.Dir(d) {
+		if m.isDir(d) {
+			break
+		}
+		if m.exists(d) {
+			return &fs.PathError{Op: "mkdir", Path: name, Err: errors.New("not a directory")}
+		}
+		dirs = append(dirs, d)
+	}
+	for _, d := range dirs {
+		m.files[d] = &fstest.MapFile{Mode: fs.ModeDir | perm.Perm(), ModTime: time.Now()}
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"io/fs"
+	"testing"
+)
+
+func Test_MemoryFS(t *testing.T) {
+	m := NewMemory()
+	assert.NoError(t, m.MkdirAll("/docs/decisions", 0755))
+	assert.NoError(t, m.WriteFile("docs/decisions/001-a.md", []byte("# 1. A\n"), 0644))
+	assert.NoError(t, m.WriteFile("/docs/decisions/002-b.md", []byte("# 2. B\n"), 0600))
+
+	matches, err := fs.Glob(m, "docs/decisions/*.md")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"docs/decisions/001-a.md", "docs/decisions/002-b.md"}, matches)
+	b, err := fs.ReadFile(m, "./docs/decisions/002-b.md")
+	assert.NoError(t, err)
+	assert.Equal(t, "# 2. B\n", string(b))
+	fi, err := m.Stat("docs/decisions/002-b.md")
+	assert.NoError(t, err)
+	assert.Equal(t, fs.FileMode(0600), fi.Mode())
+
+	var walked []string
+	assert.NoError(t, WalkDir(m, "/docs", func(p string, d fs.DirEntry, err error) error {
+		walked = append(walked, p)
+		return err
+	}))
+	assert.Equal(t, []string{"/docs", "/docs/decisions", "/docs/decisions/001-a.md", "/docs/decisions/002-b.md"}, walked)
+}
+
+func Test_MemoryWrites(t *testing.T) {
+	m := NewMemory()
+	err := m.WriteFile("docs/001-a.md", nil, 0644)
+	assert.True(t, errors.Is(err, fs.ErrNotExist), "the directory must exist")
+	assert.NoError(t, m.MkdirAll("docs", 0755))
+	assert.NoError(t, m.WriteFile("docs/001-a.md", []byte("a"), 0644))
+	assert.Error(t, m.MkdirAll("docs/001-a.md/x", 0755))
+	assert.Error(t, m.WriteFile("docs", nil, 0644))
+
+	assert.NoError(t, m.Rename("docs/001-a.md", "docs/001-b.md"))
+	_, err = m.Stat("docs/001-a.md")
+	assert.True(t, errors.Is(err, fs.ErrNotExist))
+	assert.NoError(t, m.Rename("docs", "decisions"))
+	b, err := m.ReadFile("decisions/001-b.md")
+	assert.NoError(t, err)
+	assert.Equal(t, "a", string(b))
+	assert.True(t, errors.Is(m.Rename("docs", "x"), fs.ErrNotExist))
+
+	assert.Error(t, m.Remove("decisions"), "the directory is not empty")
+	assert.NoError(t, m.Remove("decisions/001-b.md"))
+	assert.NoError(t, m.Remove("decisions"))
+	assert.True(t, errors.Is(m.Remove("decisions"), fs.ErrNotExist))
+}
@@ -0,0 +1,88 @@
+// Package storage is the file system records are read from and written to. FS is an io/fs file system with the
+// write operations adr needs added, so the helpers of io/fs (fs.ReadFile, fs.WalkDir, fs.Glob...) work with every
+// implementation: OS for the disk, Memory for tests and previews, and GitTree to read a tree straight from the
+// object store of a git repository.
+package storage
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// FS is a file system adr can read records from and write them to. Names are the paths adr uses for files:
+// relative to the working directory or absolute. OS takes them as they are, the other implementations clean them
+// into io/fs paths, so the names given to them must not go up from their root with '..'.
+type FS interface {
+	fs.StatFS
+	fs.ReadFileFS
+	// WriteFile writes data to the named file, creating it with perm if necessary. Its directory must exist.
+	WriteFile(name string, data []byte, perm fs.FileMode) error
+	// Remove removes the named file or empty directory
+	Remove(name string) error
+	// Rename moves oldname to newname, replacing newname if it is a file
+	Rename(oldname, newname string) error
+	// MkdirAll makes the named directory and any missing parents
+	MkdirAll(name string, perm fs.FileMode) error
+}
+
+// ErrReadOnly is returned by the write operations of read-only file systems such as GitTree
+var ErrReadOnly = errors.New("read-only file system")
+
+// OS is the file system of the disk
+var OS FS = osFS{}
+
+type osFS struct{}
+
+func (osFS) Open(name string) (fs.File, error) {
+	return os.Open(name)
+}
+
+func (osFS) Stat(name string) (fs.FileInfo, error) {
+	return os.Stat(name)
+}
+
+func (osFS) ReadFile(name string) ([]byte, error) {
+	return os.ReadFile(name)
+}
+
+// WriteFile writes and syncs the file, giving it perm whether or not it already existed
+func (osFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Chmod(perm)
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if cErr := f.Close(); err == nil {
+		err = cErr
+	}
+	return err
+}
+
+func (osFS) Remove(name string) error {
+	return os.Remove(name)
+}
+
+func (osFS) Rename(oldname, newname string) error {
+	return os.Rename(oldname, newname)
+}
+
+func (osFS) MkdirAll(name string, perm fs.FileMode) error {
+	return os.MkdirAll(name, perm)
+}
+
+// WalkDir walks the tree at root like fs.WalkDir. Paths are given to fn the way root is written, with the path
+// separator of the platform for OS.
+func WalkDir(fsys FS, root string, fn fs.WalkDirFunc) error {
+	if fsys == OS {
+		return filepath.WalkDir(root, fn)
+	}
+	return fs.WalkDir(fsys, root, fn)
+}